@@ -0,0 +1,239 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	influxqu "github.com/XIELongDragon/go-influx-qu"
+)
+
+var defaultKeys = tagKeys{
+	key:         "influxqu",
+	measurement: "measurement",
+	tag:         "tag",
+	field:       "field",
+	timestamp:   "timestamp",
+}
+
+func checkSource(t *testing.T, src string) *types.Package {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, "src.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+
+	pkg, err := cfg.Check("example.com/src", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pkg
+}
+
+func Test_Generate_Golden(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.go")
+	if err := run("internal/example", "Data,Event,Log,CPU,Disk", output, &defaultKeys); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile("internal/example/example_influxqu.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(expected) {
+		t.Error("generated code is out of date, run go generate ./cmd/influxqu-gen/...")
+	}
+}
+
+func Test_Generate_Errors(t *testing.T) {
+	cases := map[string]string{
+		"duplicated tag": `type Data struct {
+			M  string ` + "`influxqu:\"measurement\"`" + `
+			T1 string ` + "`influxqu:\"tag,t\"`" + `
+			T2 string ` + "`influxqu:\"tag,t\"`" + `
+		}`,
+		"no field name": `type Data struct {
+			M string ` + "`influxqu:\"measurement\"`" + `
+			F int    ` + "`influxqu:\"field\"`" + `
+		}`,
+		"unsupported option": `type Data struct {
+			M string ` + "`influxqu:\"measurement\"`" + `
+			F int    ` + "`influxqu:\"field,f,bogus\"`" + `
+		}`,
+		"unsupported field type": `type Data struct {
+			M string   ` + "`influxqu:\"measurement\"`" + `
+			F []string ` + "`influxqu:\"field,f\"`" + `
+		}`,
+		"expand": `type Data struct {
+			M     string    ` + "`influxqu:\"measurement\"`" + `
+			Cores []float64 ` + "`influxqu:\"field,core,expand\"`" + `
+		}`,
+		"measurement name": `type Data struct {
+			M string ` + "`influxqu:\"measurement,name=cpu\"`" + `
+			F int    ` + "`influxqu:\"field,f\"`" + `
+		}`,
+		"field marshaler": `type Money int64
+
+		func (m Money) MarshalInfluxField() (any, error) { return int64(m), nil }
+
+		type Data struct {
+			M     string ` + "`influxqu:\"measurement\"`" + `
+			Price Money  ` + "`influxqu:\"field,price\"`" + `
+		}`,
+		"recursive inline": `type Data struct {
+			M    string ` + "`influxqu:\"measurement\"`" + `
+			F    int    ` + "`influxqu:\"field,f\"`" + `
+			Next *Data  ` + "`influxqu:\"inline,prefix=next_\"`" + `
+		}`,
+		"recursive embedding": `type Data struct {
+			*Data
+			M string ` + "`influxqu:\"measurement\"`" + `
+			F int    ` + "`influxqu:\"field,f\"`" + `
+		}`,
+		"dynamic tags": `type Data struct {
+			M      string            ` + "`influxqu:\"measurement\"`" + `
+			Labels map[string]string ` + "`influxqu:\"tags\"`" + `
+		}`,
+		"no measurement": `type Data struct {
+			F int ` + "`influxqu:\"field,f\"`" + `
+		}`,
+	}
+
+	for name, src := range cases {
+		pkg := checkSource(t, "package src\n\n"+src)
+
+		if _, err := generate(pkg, []string{"Data"}, &defaultKeys); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+
+		if _, err := generate(pkg, nil, &defaultKeys); err == nil || !strings.HasPrefix(err.Error(), "no struct with influxqu tags") {
+			t.Errorf("%s: the type is not skipped without -type, got: %v", name, err)
+		}
+	}
+}
+
+func Test_Generate_Default_Mode(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.go")
+	if err := run("internal/example", "", output, &defaultKeys); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"CPU", "Data", "Disk", "Event", "Log"} {
+		if !strings.Contains(string(got), "func (v *"+name+") ToInfluxPoint()") {
+			t.Errorf("%s is not generated", name)
+		}
+	}
+
+	for _, name := range []string{"Host", "Meta", "Reading"} {
+		if strings.Contains(string(got), "func (v *"+name+")") {
+			t.Errorf("helper struct %s is generated", name)
+		}
+	}
+
+	pkg := checkSource(t, `package src
+
+import "time"
+
+type Point struct {
+	M string `+"`influxqu:\"measurement\"`"+`
+	F int    `+"`influxqu:\"field,f\"`"+`
+}
+
+func (p *Point) MarshalInfluxPoint() (string, map[string]string, map[string]any, time.Time, error) {
+	return p.M, nil, map[string]any{"f": p.F}, time.Time{}, nil
+}
+
+type Dynamic struct {
+	M      string             `+"`influxqu:\"measurement\"`"+`
+	Values map[string]float64 `+"`influxqu:\"fields\"`"+`
+}
+
+type Data struct {
+	M string `+"`influxqu:\"measurement\"`"+`
+	F int    `+"`influxqu:\"field,f\"`"+`
+}
+`)
+
+	src, err := generate(pkg, nil, &defaultKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(src), "func (v *Data) ToInfluxPoint()") || strings.Contains(string(src), "Point)") || strings.Contains(string(src), "Dynamic") {
+		t.Errorf("unexpected generated code:\n%s", src)
+	}
+}
+
+func Test_Generate_Skips_Untagged_Types(t *testing.T) {
+	pkg := checkSource(t, `package src
+
+type Plain struct{ A int }
+
+type Generic[T any] struct {
+	M string `+"`influxqu:\"measurement\"`"+`
+	F T      `+"`influxqu:\"field,f\"`"+`
+}
+
+type Data struct {
+	M string `+"`influxqu:\"measurement\"`"+`
+	F int    `+"`influxqu:\"field,f\"`"+`
+}
+`)
+
+	src, err := generate(pkg, nil, &defaultKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(src), "func (v *Data) ToInfluxPoint()") || strings.Contains(string(src), "Plain") {
+		t.Errorf("unexpected generated code:\n%s", src)
+	}
+
+	if _, err := generate(pkg, []string{"Plain"}, &defaultKeys); err == nil {
+		t.Error("no error for a type without tags")
+	}
+}
+
+func Test_Generate_Naming(t *testing.T) {
+	pkg := checkSource(t, `package src
+
+type Data struct {
+	M        string  `+"`influxqu:\"measurement\"`"+`
+	HostName string  `+"`influxqu:\"tag\"`"+`
+	CPUUsage float64 `+"`influxqu:\"field,,omitempty\"`"+`
+}
+`)
+
+	keys := defaultKeys
+	keys.naming = influxqu.NamingSnakeCase
+
+	src, err := generate(pkg, nil, &keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(src), `tags["host_name"]`) || !strings.Contains(string(src), `fields["cpu_usage"]`) {
+		t.Errorf("names are not derived, got:\n%s", src)
+	}
+}
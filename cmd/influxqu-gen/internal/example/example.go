@@ -0,0 +1,86 @@
+// Package example holds the structs used to test influxqu-gen.
+package example
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+//go:generate go run github.com/XIELongDragon/go-influx-qu/cmd/influxqu-gen -type Data,Event,Log,CPU,Disk
+
+type Level int
+
+func (l Level) String() string {
+	switch l {
+	case 0:
+		return "low"
+	case 1:
+		return "high"
+	}
+
+	return "unknown"
+}
+
+type Host struct {
+	Name   string `influxqu:"tag,host"`
+	Region string `influxqu:"tag,region,omitempty"`
+}
+
+type Meta struct {
+	Comment string `influxqu:"field,comment,omitempty"`
+}
+
+type Data struct {
+	Host
+	*Meta
+	Measurement string           `influxqu:"measurement"`
+	Level       Level            `influxqu:"tag,level"`
+	Usage       float64          `influxqu:"field,usage"`
+	Count       int32            `influxqu:"field,count"`
+	Total       uint64           `influxqu:"field,total"`
+	Errors      uint16           `influxqu:"field,errors"`
+	Dropped     int64            `influxqu:"field,dropped,type=uint"`
+	Ok          bool             `influxqu:"field,ok"`
+	Price       decimal.Decimal  `influxqu:"field,price"`
+	Amount      decimal.Decimal  `influxqu:"field,amount,decimal=scaled:4"`
+	Balance     *decimal.Decimal `influxqu:"field,balance,decimal=string"`
+	Uptime      time.Duration    `influxqu:"field,uptime"`
+	Temperature *float32         `influxqu:"field,temperature"`
+	Load        *int             `influxqu:"field,load,omitempty"`
+	Timestamp   time.Time        `influxqu:"timestamp"`
+}
+
+type Reading struct {
+	Value float64 `influxqu:"field,value"`
+	Unit  string  `influxqu:"tag,unit,omitempty"`
+}
+
+type Event struct {
+	Name     string   `influxqu:"measurement"`
+	Value    int      `influxqu:"field,value"`
+	Sensor   Reading  `influxqu:"inline,prefix=sensor_"`
+	Humidity *Reading `influxqu:"inline,prefix=hum_"`
+	At       int64    `influxqu:"timestamp,unix=ms"`
+}
+
+type Log struct {
+	Name    string  `influxqu:"measurement"`
+	Message string  `influxqu:"field,message"`
+	At      *string `influxqu:"timestamp,layout=RFC3339"`
+}
+
+type CPU struct {
+	_     struct{} `influxqu:"measurement,name=cpu"`
+	Host  string   `influxqu:"tag,host"`
+	Usage float64  `influxqu:"field,usage"`
+}
+
+type Disk struct {
+	Device string `influxqu:"tag,device"`
+	Free   uint64 `influxqu:"field,free"`
+}
+
+func (d *Disk) InfluxMeasurement() string {
+	return "disk"
+}
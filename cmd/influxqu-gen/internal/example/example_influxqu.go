@@ -0,0 +1,676 @@
+// Code generated by influxqu-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"strconv"
+	"time"
+
+	influxqu "github.com/XIELongDragon/go-influx-qu"
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+func (v *Data) ToInfluxPoint() (*write.Point, error) {
+	measurement := v.Measurement
+	if measurement == "" {
+		return nil, &influxqu.NoValidMeasurement{}
+	}
+	tags := make(map[string]string, 3)
+	tags["host"] = v.Host.Name
+	tags["level"] = v.Level.String()
+	if v.Host.Region != "" {
+		tag2 := v.Host.Region
+		if tag2 != "" {
+			tags["region"] = tag2
+		}
+	}
+	fields := make(map[string]any, 13)
+	field0, err := influxqu.ScaleDecimal("amount", v.Amount, 4)
+	if err != nil {
+		return nil, err
+	}
+	fields["amount"] = field0
+	if v.Balance != nil {
+		fields["balance"] = (*v.Balance).String()
+	}
+	if v.Meta != nil {
+		if v.Meta.Comment != "" {
+			fields["comment"] = v.Meta.Comment
+		}
+	}
+	fields["count"] = v.Count
+	field4, err := influxqu.UnsignedValue("dropped", v.Dropped)
+	if err != nil {
+		return nil, err
+	}
+	fields["dropped"] = field4
+	fields["errors"] = uint64(v.Errors)
+	if v.Load != nil {
+		fields["load"] = *v.Load
+	}
+	fields["ok"] = v.Ok
+	fields["price"] = v.Price.InexactFloat64()
+	if v.Temperature != nil {
+		fields["temperature"] = *v.Temperature
+	}
+	fields["total"] = v.Total
+	fields["uptime"] = v.Uptime
+	fields["usage"] = v.Usage
+	if len(fields) == 0 {
+		return nil, &influxqu.NoValidField{}
+	}
+	ts := v.Timestamp
+	return influxdb2.NewPoint(measurement, tags, fields, ts), nil
+}
+
+func (v *Data) AppendLineProtocol(dst []byte, precision time.Duration) ([]byte, error) {
+	var err error
+	measurement := v.Measurement
+	if measurement == "" {
+		return nil, &influxqu.NoValidMeasurement{}
+	}
+	dst = influxqu.AppendMeasurement(dst, measurement)
+	tag0 := v.Host.Name
+	if tag0 != "" {
+		dst = append(dst, ",host="...)
+		dst = influxqu.AppendTagValue(dst, tag0)
+	}
+	tag1 := v.Level.String()
+	if tag1 != "" {
+		dst = append(dst, ",level="...)
+		dst = influxqu.AppendTagValue(dst, tag1)
+	}
+	if v.Host.Region != "" {
+		tag2 := v.Host.Region
+		if tag2 != "" {
+			dst = append(dst, ",region="...)
+			dst = influxqu.AppendTagValue(dst, tag2)
+		}
+	}
+	sep := byte(' ')
+	dst = append(append(dst, sep), "amount="...)
+	sep = ','
+	field0, err := influxqu.ScaleDecimal("amount", v.Amount, 4)
+	if err != nil {
+		return nil, err
+	}
+	dst = append(strconv.AppendInt(dst, field0, 10), 'i')
+	if v.Balance != nil {
+		dst = append(append(dst, sep), "balance="...)
+		sep = ','
+		dst = influxqu.AppendStringValue(dst, (*v.Balance).String())
+	}
+	if v.Meta != nil {
+		if v.Meta.Comment != "" {
+			dst = append(append(dst, sep), "comment="...)
+			sep = ','
+			dst = influxqu.AppendStringValue(dst, v.Meta.Comment)
+		}
+	}
+	dst = append(append(dst, sep), "count="...)
+	sep = ','
+	dst = append(strconv.AppendInt(dst, int64(v.Count), 10), 'i')
+	dst = append(append(dst, sep), "dropped="...)
+	sep = ','
+	field4, err := influxqu.UnsignedValue("dropped", v.Dropped)
+	if err != nil {
+		return nil, err
+	}
+	dst = append(strconv.AppendUint(dst, field4, 10), 'u')
+	dst = append(append(dst, sep), "errors="...)
+	sep = ','
+	dst = append(strconv.AppendUint(dst, uint64(v.Errors), 10), 'u')
+	if v.Load != nil {
+		dst = append(append(dst, sep), "load="...)
+		sep = ','
+		dst = append(strconv.AppendInt(dst, int64(*v.Load), 10), 'i')
+	}
+	dst = append(append(dst, sep), "ok="...)
+	sep = ','
+	dst = strconv.AppendBool(dst, v.Ok)
+	dst = append(append(dst, sep), "price="...)
+	sep = ','
+	if dst, err = influxqu.AppendFloatValue(dst, v.Price.InexactFloat64()); err != nil {
+		return nil, err
+	}
+	if v.Temperature != nil {
+		dst = append(append(dst, sep), "temperature="...)
+		sep = ','
+		if dst, err = influxqu.AppendFloatValue(dst, float64(*v.Temperature)); err != nil {
+			return nil, err
+		}
+	}
+	dst = append(append(dst, sep), "total="...)
+	sep = ','
+	dst = append(strconv.AppendUint(dst, v.Total, 10), 'u')
+	dst = append(append(dst, sep), "uptime="...)
+	sep = ','
+	dst = influxqu.AppendStringValue(dst, v.Uptime.String())
+	dst = append(append(dst, sep), "usage="...)
+	sep = ','
+	if dst, err = influxqu.AppendFloatValue(dst, v.Usage); err != nil {
+		return nil, err
+	}
+	if sep == ' ' {
+		return nil, &influxqu.NoValidField{}
+	}
+	ts := v.Timestamp
+	dst = append(dst, ' ')
+	dst = influxqu.AppendTimestamp(dst, ts, precision)
+	return append(dst, '\n'), nil
+}
+
+func (v *Data) FromFluxRecord(rec *query.FluxRecord) error {
+	if x, ok := influxqu.FluxRecordValue(rec, "host"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("host", x)
+		if err != nil {
+			return err
+		}
+		v.Host.Name = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "region"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("region", x)
+		if err != nil {
+			return err
+		}
+		v.Host.Region = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "comment"); ok && x != nil {
+		if v.Meta == nil {
+			v.Meta = new(Meta)
+		}
+		tmp, err := influxqu.DecodeString("comment", x)
+		if err != nil {
+			return err
+		}
+		v.Meta.Comment = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "_measurement"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("_measurement", x)
+		if err != nil {
+			return err
+		}
+		v.Measurement = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "level"); ok && x != nil {
+		tmp, err := influxqu.DecodeInt("level", x, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		v.Level = Level(tmp)
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "usage"); ok && x != nil {
+		tmp, err := influxqu.DecodeFloat("usage", x)
+		if err != nil {
+			return err
+		}
+		v.Usage = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "count"); ok && x != nil {
+		tmp, err := influxqu.DecodeInt("count", x, 32)
+		if err != nil {
+			return err
+		}
+		v.Count = int32(tmp)
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "total"); ok && x != nil {
+		tmp, err := influxqu.DecodeUint("total", x, 64)
+		if err != nil {
+			return err
+		}
+		v.Total = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "errors"); ok && x != nil {
+		tmp, err := influxqu.DecodeUint("errors", x, 16)
+		if err != nil {
+			return err
+		}
+		v.Errors = uint16(tmp)
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "dropped"); ok && x != nil {
+		tmp, err := influxqu.DecodeInt("dropped", x, 64)
+		if err != nil {
+			return err
+		}
+		v.Dropped = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "ok"); ok && x != nil {
+		tmp, err := influxqu.DecodeBool("ok", x)
+		if err != nil {
+			return err
+		}
+		v.Ok = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "price"); ok && x != nil {
+		tmp, err := influxqu.DecodeDecimal("price", x)
+		if err != nil {
+			return err
+		}
+		v.Price = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "amount"); ok && x != nil {
+		tmp, err := influxqu.DecodeDecimal("amount", x)
+		if err != nil {
+			return err
+		}
+		v.Amount = tmp.Shift(-4)
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "balance"); ok && x != nil {
+		tmp, err := influxqu.DecodeDecimal("balance", x)
+		if err != nil {
+			return err
+		}
+		conv := tmp
+		v.Balance = &conv
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "uptime"); ok && x != nil {
+		tmp, err := influxqu.DecodeInt("uptime", x, 64)
+		if err != nil {
+			return err
+		}
+		v.Uptime = time.Duration(tmp)
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "temperature"); ok && x != nil {
+		tmp, err := influxqu.DecodeFloat("temperature", x)
+		if err != nil {
+			return err
+		}
+		conv := float32(tmp)
+		v.Temperature = &conv
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "load"); ok && x != nil {
+		tmp, err := influxqu.DecodeInt("load", x, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		conv := int(tmp)
+		v.Load = &conv
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "_time"); ok && x != nil {
+		tmp, err := influxqu.DecodeTime("_time", x)
+		if err != nil {
+			return err
+		}
+		v.Timestamp = tmp
+	}
+
+	return nil
+}
+
+func (v *Data) InfluxGeneratedKeys() influxqu.GeneratedKeys {
+	return influxqu.GeneratedKeys{Key: "influxqu", Measurement: "measurement", Tag: "tag", Field: "field", Timestamp: "timestamp", Naming: influxqu.NamingRequired}
+}
+
+func (v *Event) ToInfluxPoint() (*write.Point, error) {
+	measurement := v.Name
+	if measurement == "" {
+		return nil, &influxqu.NoValidMeasurement{}
+	}
+	tags := make(map[string]string, 2)
+	if v.Humidity != nil {
+		if v.Humidity.Unit != "" {
+			tag0 := v.Humidity.Unit
+			if tag0 != "" {
+				tags["hum_unit"] = tag0
+			}
+		}
+	}
+	if v.Sensor.Unit != "" {
+		tag1 := v.Sensor.Unit
+		if tag1 != "" {
+			tags["sensor_unit"] = tag1
+		}
+	}
+	fields := make(map[string]any, 3)
+	if v.Humidity != nil {
+		fields["hum_value"] = v.Humidity.Value
+	}
+	fields["sensor_value"] = v.Sensor.Value
+	fields["value"] = v.Value
+	if len(fields) == 0 {
+		return nil, &influxqu.NoValidField{}
+	}
+	ts := time.UnixMilli(v.At)
+	return influxdb2.NewPoint(measurement, tags, fields, ts), nil
+}
+
+func (v *Event) AppendLineProtocol(dst []byte, precision time.Duration) ([]byte, error) {
+	var err error
+	measurement := v.Name
+	if measurement == "" {
+		return nil, &influxqu.NoValidMeasurement{}
+	}
+	dst = influxqu.AppendMeasurement(dst, measurement)
+	if v.Humidity != nil {
+		if v.Humidity.Unit != "" {
+			tag0 := v.Humidity.Unit
+			if tag0 != "" {
+				dst = append(dst, ",hum_unit="...)
+				dst = influxqu.AppendTagValue(dst, tag0)
+			}
+		}
+	}
+	if v.Sensor.Unit != "" {
+		tag1 := v.Sensor.Unit
+		if tag1 != "" {
+			dst = append(dst, ",sensor_unit="...)
+			dst = influxqu.AppendTagValue(dst, tag1)
+		}
+	}
+	sep := byte(' ')
+	if v.Humidity != nil {
+		dst = append(append(dst, sep), "hum_value="...)
+		sep = ','
+		if dst, err = influxqu.AppendFloatValue(dst, v.Humidity.Value); err != nil {
+			return nil, err
+		}
+	}
+	dst = append(append(dst, sep), "sensor_value="...)
+	sep = ','
+	if dst, err = influxqu.AppendFloatValue(dst, v.Sensor.Value); err != nil {
+		return nil, err
+	}
+	dst = append(append(dst, sep), "value="...)
+	sep = ','
+	dst = append(strconv.AppendInt(dst, int64(v.Value), 10), 'i')
+	if sep == ' ' {
+		return nil, &influxqu.NoValidField{}
+	}
+	ts := time.UnixMilli(v.At)
+	dst = append(dst, ' ')
+	dst = influxqu.AppendTimestamp(dst, ts, precision)
+	return append(dst, '\n'), nil
+}
+
+func (v *Event) FromFluxRecord(rec *query.FluxRecord) error {
+	if x, ok := influxqu.FluxRecordValue(rec, "_measurement"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("_measurement", x)
+		if err != nil {
+			return err
+		}
+		v.Name = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "value"); ok && x != nil {
+		tmp, err := influxqu.DecodeInt("value", x, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		v.Value = int(tmp)
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "sensor_value"); ok && x != nil {
+		tmp, err := influxqu.DecodeFloat("sensor_value", x)
+		if err != nil {
+			return err
+		}
+		v.Sensor.Value = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "sensor_unit"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("sensor_unit", x)
+		if err != nil {
+			return err
+		}
+		v.Sensor.Unit = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "hum_value"); ok && x != nil {
+		if v.Humidity == nil {
+			v.Humidity = new(Reading)
+		}
+		tmp, err := influxqu.DecodeFloat("hum_value", x)
+		if err != nil {
+			return err
+		}
+		v.Humidity.Value = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "hum_unit"); ok && x != nil {
+		if v.Humidity == nil {
+			v.Humidity = new(Reading)
+		}
+		tmp, err := influxqu.DecodeString("hum_unit", x)
+		if err != nil {
+			return err
+		}
+		v.Humidity.Unit = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "_time"); ok && x != nil {
+		tmp, err := influxqu.DecodeTime("_time", x)
+		if err != nil {
+			return err
+		}
+		v.At = tmp.UnixMilli()
+	}
+
+	return nil
+}
+
+func (v *Event) InfluxGeneratedKeys() influxqu.GeneratedKeys {
+	return influxqu.GeneratedKeys{Key: "influxqu", Measurement: "measurement", Tag: "tag", Field: "field", Timestamp: "timestamp", Naming: influxqu.NamingRequired}
+}
+
+func (v *Log) ToInfluxPoint() (*write.Point, error) {
+	measurement := v.Name
+	if measurement == "" {
+		return nil, &influxqu.NoValidMeasurement{}
+	}
+	tags := make(map[string]string, 0)
+	fields := make(map[string]any, 1)
+	fields["message"] = v.Message
+	if len(fields) == 0 {
+		return nil, &influxqu.NoValidField{}
+	}
+	if v.At == nil {
+		return nil, &influxqu.UnSupportedType{}
+	}
+	ts, err := time.Parse(time.RFC3339, *v.At)
+	if err != nil {
+		return nil, err
+	}
+	return influxdb2.NewPoint(measurement, tags, fields, ts), nil
+}
+
+func (v *Log) AppendLineProtocol(dst []byte, precision time.Duration) ([]byte, error) {
+	measurement := v.Name
+	if measurement == "" {
+		return nil, &influxqu.NoValidMeasurement{}
+	}
+	dst = influxqu.AppendMeasurement(dst, measurement)
+	sep := byte(' ')
+	dst = append(append(dst, sep), "message="...)
+	sep = ','
+	dst = influxqu.AppendStringValue(dst, v.Message)
+	if sep == ' ' {
+		return nil, &influxqu.NoValidField{}
+	}
+	if v.At == nil {
+		return nil, &influxqu.UnSupportedType{}
+	}
+	ts, err := time.Parse(time.RFC3339, *v.At)
+	if err != nil {
+		return nil, err
+	}
+	dst = append(dst, ' ')
+	dst = influxqu.AppendTimestamp(dst, ts, precision)
+	return append(dst, '\n'), nil
+}
+
+func (v *Log) FromFluxRecord(rec *query.FluxRecord) error {
+	if x, ok := influxqu.FluxRecordValue(rec, "_measurement"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("_measurement", x)
+		if err != nil {
+			return err
+		}
+		v.Name = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "message"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("message", x)
+		if err != nil {
+			return err
+		}
+		v.Message = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "_time"); ok && x != nil {
+		tmp, err := influxqu.DecodeTime("_time", x)
+		if err != nil {
+			return err
+		}
+		conv := tmp.Format(time.RFC3339)
+		v.At = &conv
+	}
+
+	return nil
+}
+
+func (v *Log) InfluxGeneratedKeys() influxqu.GeneratedKeys {
+	return influxqu.GeneratedKeys{Key: "influxqu", Measurement: "measurement", Tag: "tag", Field: "field", Timestamp: "timestamp", Naming: influxqu.NamingRequired}
+}
+
+func (v *CPU) ToInfluxPoint() (*write.Point, error) {
+	measurement := "cpu"
+	tags := make(map[string]string, 1)
+	tags["host"] = v.Host
+	fields := make(map[string]any, 1)
+	fields["usage"] = v.Usage
+	if len(fields) == 0 {
+		return nil, &influxqu.NoValidField{}
+	}
+	ts := time.Now()
+	return influxdb2.NewPoint(measurement, tags, fields, ts), nil
+}
+
+func (v *CPU) AppendLineProtocol(dst []byte, precision time.Duration) ([]byte, error) {
+	var err error
+	measurement := "cpu"
+	dst = influxqu.AppendMeasurement(dst, measurement)
+	tag0 := v.Host
+	if tag0 != "" {
+		dst = append(dst, ",host="...)
+		dst = influxqu.AppendTagValue(dst, tag0)
+	}
+	sep := byte(' ')
+	dst = append(append(dst, sep), "usage="...)
+	sep = ','
+	if dst, err = influxqu.AppendFloatValue(dst, v.Usage); err != nil {
+		return nil, err
+	}
+	if sep == ' ' {
+		return nil, &influxqu.NoValidField{}
+	}
+	ts := time.Now()
+	dst = append(dst, ' ')
+	dst = influxqu.AppendTimestamp(dst, ts, precision)
+	return append(dst, '\n'), nil
+}
+
+func (v *CPU) FromFluxRecord(rec *query.FluxRecord) error {
+	if x, ok := influxqu.FluxRecordValue(rec, "host"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("host", x)
+		if err != nil {
+			return err
+		}
+		v.Host = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "usage"); ok && x != nil {
+		tmp, err := influxqu.DecodeFloat("usage", x)
+		if err != nil {
+			return err
+		}
+		v.Usage = tmp
+	}
+
+	return nil
+}
+
+func (v *CPU) InfluxGeneratedKeys() influxqu.GeneratedKeys {
+	return influxqu.GeneratedKeys{Key: "influxqu", Measurement: "measurement", Tag: "tag", Field: "field", Timestamp: "timestamp", Naming: influxqu.NamingRequired}
+}
+
+func (v *Disk) ToInfluxPoint() (*write.Point, error) {
+	measurement := v.InfluxMeasurement()
+	if measurement == "" {
+		return nil, &influxqu.NoValidMeasurement{}
+	}
+	tags := make(map[string]string, 1)
+	tags["device"] = v.Device
+	fields := make(map[string]any, 1)
+	fields["free"] = v.Free
+	if len(fields) == 0 {
+		return nil, &influxqu.NoValidField{}
+	}
+	ts := time.Now()
+	return influxdb2.NewPoint(measurement, tags, fields, ts), nil
+}
+
+func (v *Disk) AppendLineProtocol(dst []byte, precision time.Duration) ([]byte, error) {
+	measurement := v.InfluxMeasurement()
+	if measurement == "" {
+		return nil, &influxqu.NoValidMeasurement{}
+	}
+	dst = influxqu.AppendMeasurement(dst, measurement)
+	tag0 := v.Device
+	if tag0 != "" {
+		dst = append(dst, ",device="...)
+		dst = influxqu.AppendTagValue(dst, tag0)
+	}
+	sep := byte(' ')
+	dst = append(append(dst, sep), "free="...)
+	sep = ','
+	dst = append(strconv.AppendUint(dst, v.Free, 10), 'u')
+	if sep == ' ' {
+		return nil, &influxqu.NoValidField{}
+	}
+	ts := time.Now()
+	dst = append(dst, ' ')
+	dst = influxqu.AppendTimestamp(dst, ts, precision)
+	return append(dst, '\n'), nil
+}
+
+func (v *Disk) FromFluxRecord(rec *query.FluxRecord) error {
+	if x, ok := influxqu.FluxRecordValue(rec, "device"); ok && x != nil {
+		tmp, err := influxqu.DecodeString("device", x)
+		if err != nil {
+			return err
+		}
+		v.Device = tmp
+	}
+
+	if x, ok := influxqu.FluxRecordValue(rec, "free"); ok && x != nil {
+		tmp, err := influxqu.DecodeUint("free", x, 64)
+		if err != nil {
+			return err
+		}
+		v.Free = tmp
+	}
+
+	return nil
+}
+
+func (v *Disk) InfluxGeneratedKeys() influxqu.GeneratedKeys {
+	return influxqu.GeneratedKeys{Key: "influxqu", Measurement: "measurement", Tag: "tag", Field: "field", Timestamp: "timestamp", Naming: influxqu.NamingRequired}
+}
@@ -0,0 +1,277 @@
+package example
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	influxqu "github.com/XIELongDragon/go-influx-qu"
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+	"github.com/shopspring/decimal"
+)
+
+// plain has the layout of Data without the generated methods, so InfluxQu reads it through reflection
+type plain Data
+
+func testData() []Data {
+	temperature := float32(36.5)
+	load := 3
+	balance := decimal.RequireFromString("12345678901234567890.123456789")
+
+	return []Data{
+		{
+			Host:        Host{Name: "server 1", Region: "eu,west"},
+			Meta:        &Meta{Comment: `say "hi"`},
+			Measurement: "cpu",
+			Level:       1,
+			Usage:       0.25,
+			Count:       -7,
+			Total:       1<<63 + 1,
+			Errors:      12,
+			Dropped:     5,
+			Ok:          true,
+			Price:       decimal.NewFromFloat(1.35),
+			Amount:      decimal.RequireFromString("-10.0125"),
+			Balance:     &balance,
+			Uptime:      time.Minute,
+			Temperature: &temperature,
+			Load:        &load,
+			Timestamp:   time.Unix(1700000000, 123456789),
+		},
+		{
+			Host:        Host{Name: "server=2"},
+			Measurement: "cpu load",
+			Timestamp:   time.Unix(1700000001, 0),
+		},
+	}
+}
+
+func Test_Generated_ToInfluxPoint(t *testing.T) {
+	q := influxqu.NewinfluxQu()
+
+	for _, d := range testData() {
+		p := plain(d)
+
+		expected, err := q.GenerateInfluxPoint(&p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := d.ToInfluxPoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Name() != expected.Name() || !got.Time().Equal(expected.Time()) {
+			t.Errorf("point is not expected, got: %s %v, expected: %s %v", got.Name(), got.Time(), expected.Name(), expected.Time())
+		}
+
+		if !reflect.DeepEqual(got.TagList(), expected.TagList()) {
+			t.Errorf("tags are not expected, got: %v, expected: %v", got.TagList(), expected.TagList())
+		}
+
+		if !reflect.DeepEqual(got.FieldList(), expected.FieldList()) {
+			t.Errorf("fields are not expected, got: %v, expected: %v", got.FieldList(), expected.FieldList())
+		}
+	}
+}
+
+func Test_Generated_AppendLineProtocol(t *testing.T) {
+	q := influxqu.NewinfluxQu()
+
+	for _, d := range testData() {
+		p := plain(d)
+
+		for _, precision := range []time.Duration{time.Nanosecond, time.Second} {
+			expected, err := q.MarshalLineProtocol(&p, precision)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := q.MarshalLineProtocol(&d, precision)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != string(expected) {
+				t.Errorf("line is not expected, got: %s, expected: %s", got, expected)
+			}
+		}
+	}
+}
+
+func Test_Generated_Errors(t *testing.T) {
+	var d Data
+	if _, err := d.AppendLineProtocol(nil, time.Nanosecond); err == nil {
+		t.Error("no error for empty measurement")
+	}
+
+	d.Measurement = "cpu"
+	d.Usage = 1
+
+	if _, err := d.ToInfluxPoint(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Generated_FromFluxRecord(t *testing.T) {
+	now := time.Now()
+	rec := query.NewFluxRecord(0, map[string]interface{}{
+		"_measurement": "cpu",
+		"_time":        now,
+		"host":         "server",
+		"level":        int64(1),
+		"comment":      "ok",
+		"usage":        0.5,
+		"count":        int64(7),
+		"total":        uint64(1<<63 + 1),
+		"errors":       uint64(12),
+		"dropped":      uint64(5),
+		"ok":           true,
+		"price":        1.35,
+		"amount":       int64(100125),
+		"balance":      "12345678901234567890.123456789",
+		"uptime":       int64(time.Second),
+		"_field":       "temperature",
+		"_value":       36.5,
+	})
+
+	q := influxqu.NewinfluxQu()
+
+	var expected plain
+	if err := q.DecodeFluxRecord(rec, &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	var got Data
+	if err := got.FromFluxRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(Data(expected), got) {
+		t.Errorf("decoded data is not expected, got: %+v, expected: %+v", got, expected)
+	}
+
+	bad := query.NewFluxRecord(0, map[string]interface{}{"count": int64(1) << 40})
+	if err := got.FromFluxRecord(bad); err == nil {
+		t.Error("no error for overflow")
+	}
+}
+
+func Test_Generated_Timestamps(t *testing.T) {
+	type plainEvent Event
+
+	type plainLog Log
+
+	q := influxqu.NewinfluxQu()
+	at := "2024-01-02T03:04:05Z"
+	event := Event{Name: "event", Value: 1, Sensor: Reading{Value: 2, Unit: "C"}, Humidity: &Reading{Value: 3}, At: 1700000000123}
+	log := Log{Name: "log", Message: "m", At: &at}
+	pe, pl := plainEvent(event), plainLog(log)
+
+	for _, c := range [][2]any{{&event, &pe}, {&log, &pl}} {
+		got, err := q.MarshalLineProtocol(c[0], time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := q.MarshalLineProtocol(c[1], time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != string(expected) {
+			t.Errorf("line is not expected, got: %s, expected: %s", got, expected)
+		}
+	}
+
+	rec := query.NewFluxRecord(0, map[string]interface{}{
+		"_time":        time.UnixMilli(1700000000123).UTC(),
+		"sensor_value": 2.0,
+		"sensor_unit":  "C",
+		"hum_value":    3.0,
+	})
+
+	var decodedEvent Event
+	if err := decodedEvent.FromFluxRecord(rec); err != nil || decodedEvent.At != event.At {
+		t.Errorf("unix timestamp is not decoded, got: %d, %v", decodedEvent.At, err)
+	}
+
+	if decodedEvent.Sensor != event.Sensor || decodedEvent.Humidity == nil || *decodedEvent.Humidity != *event.Humidity {
+		t.Errorf("inline fields are not decoded, got: %+v", decodedEvent)
+	}
+
+	var decodedLog Log
+	if err := decodedLog.FromFluxRecord(rec); err != nil || decodedLog.At == nil || *decodedLog.At != "2023-11-14T22:13:20Z" {
+		t.Errorf("layout timestamp is not decoded, got: %v, %v", decodedLog.At, err)
+	}
+}
+
+func Test_Generated_Static_Measurement(t *testing.T) {
+	type plainCPU CPU
+
+	q := influxqu.NewinfluxQu()
+	cpu := CPU{Host: "server", Usage: 0.5}
+	pc := plainCPU(cpu)
+
+	got, err := q.MarshalLineProtocol(&cpu, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := q.MarshalLineProtocol(&pc, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(expected) || !strings.HasPrefix(string(got), "cpu,host=server usage=0.5 ") {
+		t.Errorf("line is not expected, got: %s, expected: %s", got, expected)
+	}
+
+	prefixed, err := influxqu.NewinfluxQu(influxqu.WithMeasurementPrefix("app_")).MarshalLineProtocol(&cpu, time.Second)
+	if err != nil || !strings.HasPrefix(string(prefixed), "app_cpu,host=server ") {
+		t.Errorf("generated method is used with a measurement prefix, got: %s, %v", prefixed, err)
+	}
+
+	disk := Disk{Device: "sda", Free: 1}
+
+	p, err := disk.ToInfluxPoint()
+	if err != nil || p.Name() != "disk" {
+		t.Errorf("measurement is not expected, got: %v, %v", p, err)
+	}
+}
+
+func Test_Generated_Keys(t *testing.T) {
+	d := testData()[0]
+
+	q, err := influxqu.NewinfluxQuWithKeys("db", "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Data has no db tags, so the generated methods of the influxqu tags must not be used
+	if _, err := q.GenerateInfluxPoint(&d); !errors.Is(err, influxqu.ErrNoValidMeasurement) {
+		t.Errorf("expected the reflection error of the db tags, got: %v", err)
+	}
+
+	if _, err := q.MarshalLineProtocol(&d, time.Second); !errors.Is(err, influxqu.ErrNoValidMeasurement) {
+		t.Errorf("expected the reflection error of the db tags, got: %v", err)
+	}
+
+	var decoded Data
+	if err := q.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{"_measurement": "cpu"}), &decoded); err != nil || decoded.Measurement != "" {
+		t.Errorf("generated decoder is used with other keys, got: %+v, %v", decoded, err)
+	}
+
+	keys := d.InfluxGeneratedKeys()
+	if keys.Key != "influxqu" || keys.Naming != influxqu.NamingRequired {
+		t.Errorf("generated keys are not expected, got: %+v", keys)
+	}
+
+	snake := influxqu.NewinfluxQu(influxqu.WithNamingStrategy(influxqu.NamingSnakeCase))
+	if err := snake.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{"_measurement": "cpu"}), &decoded); err != nil || decoded.Measurement != "cpu" {
+		t.Errorf("decoded data is not expected, got: %+v, %v", decoded, err)
+	}
+}
@@ -0,0 +1,132 @@
+// influxqu-gen generates reflection free encoders and decoders for structs tagged with influxqu.
+//
+// For every selected struct it writes ToInfluxPoint, AppendLineProtocol and FromFluxRecord
+// methods, InfluxQu calls these methods instead of reading the struct through reflection.
+// Without -type every struct with influxqu tags is selected, the ones the generator does not
+// support (no measurement, tags or fields maps, custom marshalers...) are left to reflection.
+//
+//	//go:generate go run github.com/XIELongDragon/go-influx-qu/cmd/influxqu-gen -type Data
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	influxqu "github.com/XIELongDragon/go-influx-qu"
+)
+
+var namingStrategies = map[string]influxqu.NamingStrategy{
+	"":      influxqu.NamingRequired,
+	"asis":  influxqu.NamingAsIs,
+	"snake": influxqu.NamingSnakeCase,
+	"camel": influxqu.NamingCamelCase,
+	"lower": influxqu.NamingLowerCase,
+}
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma separated list of type names, default to all structs with influxqu tags")
+		output    = flag.String("output", "", "output file name, default to <package>_influxqu.go")
+		naming    = flag.String("naming", "", "naming strategy of unnamed tags and fields: asis, snake, camel or lower")
+		keys      tagKeys
+	)
+
+	flag.StringVar(&keys.key, "key", "influxqu", "struct tag key")
+	flag.StringVar(&keys.measurement, "measurement", "measurement", "measurement keyword")
+	flag.StringVar(&keys.tag, "tag", "tag", "tag keyword")
+	flag.StringVar(&keys.field, "field", "field", "field keyword")
+	flag.StringVar(&keys.timestamp, "timestamp", "timestamp", "timestamp keyword")
+	flag.Parse()
+
+	strategy, ok := namingStrategies[*naming]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "influxqu-gen: unknown naming strategy %q\n", *naming)
+		os.Exit(2)
+	}
+
+	keys.naming = strategy
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, *typeNames, *output, &keys); err != nil {
+		fmt.Fprintln(os.Stderr, "influxqu-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// load type checks the package in dir from source, leaving out generated files
+func load(dir string) (*types.Package, []string, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	names := make([]string, 0, len(bp.GoFiles))
+
+	for _, name := range bp.GoFiles {
+		path := filepath.Join(bp.Dir, name)
+
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// skip previously generated code so a stale file does not break the next run
+		if ast.IsGenerated(f) {
+			continue
+		}
+
+		files = append(files, f)
+		names = append(names, path)
+	}
+
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no go files in %s", bp.Dir)
+	}
+
+	cfg := &types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+
+	pkg, err := cfg.Check(bp.ImportPath, fset, files, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pkg, names, nil
+}
+
+func run(dir, typeNames, output string, keys *tagKeys) error {
+	pkg, files, err := load(dir)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if typeNames != "" {
+		names = strings.Split(typeNames, ",")
+	}
+
+	src, err := generate(pkg, names, keys)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = filepath.Join(filepath.Dir(files[0]), strings.ToLower(pkg.Name())+"_influxqu.go")
+	}
+
+	return os.WriteFile(output, src, 0o644)
+}
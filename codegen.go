@@ -0,0 +1,252 @@
+package influxqu
+
+import (
+	"encoding"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/shopspring/decimal"
+)
+
+// The interfaces below are implemented by the code influxqu-gen generates,
+// InfluxQu calls them instead of reading the struct through reflection.
+
+type InfluxPointGenerator interface {
+	ToInfluxPoint() (*write.Point, error)
+}
+
+type LineProtocolAppender interface {
+	AppendLineProtocol(dst []byte, precision time.Duration) ([]byte, error)
+}
+
+type FluxRecordDecoder interface {
+	FromFluxRecord(rec *query.FluxRecord) error
+}
+
+// GeneratedKeys are the struct tag key, keywords and naming strategy the methods of a type were generated with
+type GeneratedKeys struct {
+	Key         string
+	Measurement string
+	Tag         string
+	Field       string
+	Timestamp   string
+	Naming      NamingStrategy
+}
+
+type InfluxKeysReporter interface {
+	InfluxGeneratedKeys() GeneratedKeys
+}
+
+// defaultGeneratedKeys are assumed for a type implementing the methods without InfluxGeneratedKeys
+var defaultGeneratedKeys = GeneratedKeys{
+	Key:         "influxqu",
+	Measurement: "measurement",
+	Tag:         "tag",
+	Field:       "field",
+	Timestamp:   "timestamp",
+	Naming:      NamingRequired,
+}
+
+// generatedFor reports whether the generated methods of v read the struct tags the way q does,
+// otherwise q falls back to reflection
+func (q *influxQu) generatedFor(v any) bool {
+	keys := defaultGeneratedKeys
+	if r, ok := v.(InfluxKeysReporter); ok {
+		keys = r.InfluxGeneratedKeys()
+	}
+
+	return keys == GeneratedKeys{
+		Key:         q.key,
+		Measurement: q.measurementKey,
+		Tag:         q.tagKey,
+		Field:       q.fieldKey,
+		Timestamp:   q.timestampKey,
+		Naming:      q.naming,
+	}
+}
+
+// The helpers below are used by the generated code, they write and read
+// values the same way as the reflection based encoder and decoder.
+
+func AppendMeasurement(dst []byte, s string) []byte {
+	return appendMeasurement(dst, s)
+}
+
+func AppendTagValue(dst []byte, s string) []byte {
+	return appendKey(dst, s)
+}
+
+func AppendStringValue(dst []byte, s string) []byte {
+	return appendStringValue(dst, s)
+}
+
+func AppendFloatValue(dst []byte, f float64) ([]byte, error) {
+	return appendFloatValue(dst, f)
+}
+
+func AppendTimestamp(dst []byte, ts time.Time, precision time.Duration) []byte {
+	return appendTimestamp(dst, ts, precision)
+}
+
+func FluxRecordValue(rec *query.FluxRecord, column string) (any, bool) {
+	values := rec.Values()
+	if v, ok := values[column]; ok {
+		return v, true
+	}
+
+	if f, ok := values[fluxFieldColumn].(string); ok && f == column {
+		return values[fluxValueColumn], true
+	}
+
+	return nil, false
+}
+
+var (
+	intTypes = map[int]reflect.Type{
+		8: reflect.TypeOf(int8(0)), 16: reflect.TypeOf(int16(0)), 32: reflect.TypeOf(int32(0)), 64: reflect.TypeOf(int64(0)),
+	}
+	uintTypes = map[int]reflect.Type{
+		8: reflect.TypeOf(uint8(0)), 16: reflect.TypeOf(uint16(0)), 32: reflect.TypeOf(uint32(0)), 64: reflect.TypeOf(uint64(0)),
+	}
+)
+
+func DecodeString(column string, v any) (string, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	}
+
+	return "", &MismatchedType{column: column, target: reflect.TypeOf(""), value: v}
+}
+
+func DecodeInt(column string, v any, bits int) (int64, error) {
+	var i int64
+
+	switch n := v.(type) {
+	case int64:
+		i = n
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, &MismatchedType{column: column, target: intTypes[bits], value: v}
+		}
+
+		i = int64(n)
+	default:
+		if !setInt(reflect.ValueOf(&i).Elem(), v) {
+			return 0, &MismatchedType{column: column, target: intTypes[bits], value: v}
+		}
+	}
+
+	if bits < 64 && (i < -1<<(bits-1) || i > 1<<(bits-1)-1) {
+		return 0, &MismatchedType{column: column, target: intTypes[bits], value: v}
+	}
+
+	return i, nil
+}
+
+func DecodeUint(column string, v any, bits int) (uint64, error) {
+	var u uint64
+
+	switch n := v.(type) {
+	case uint64:
+		u = n
+	case int64:
+		if n < 0 {
+			return 0, &MismatchedType{column: column, target: uintTypes[bits], value: v}
+		}
+
+		u = uint64(n)
+	default:
+		if !setUint(reflect.ValueOf(&u).Elem(), v) {
+			return 0, &MismatchedType{column: column, target: uintTypes[bits], value: v}
+		}
+	}
+
+	if bits < 64 && u > 1<<bits-1 {
+		return 0, &MismatchedType{column: column, target: uintTypes[bits], value: v}
+	}
+
+	return u, nil
+}
+
+func DecodeFloat(column string, v any) (float64, error) {
+	if f, ok := v.(float64); ok {
+		return f, nil
+	}
+
+	f, ok := toFloat64(v)
+	if !ok {
+		return 0, &MismatchedType{column: column, target: reflect.TypeOf(float64(0)), value: v}
+	}
+
+	return f, nil
+}
+
+func DecodeBool(column string, v any) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		if tmp, err := strconv.ParseBool(b); err == nil {
+			return tmp, nil
+		}
+	}
+
+	return false, &MismatchedType{column: column, target: reflect.TypeOf(false), value: v}
+}
+
+func DecodeTime(column string, v any) (time.Time, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, &MismatchedType{column: column, target: reflect.TypeOf(time.Time{}), value: v}
+	}
+
+	return t, nil
+}
+
+func DecodeDecimal(column string, v any) (decimal.Decimal, error) {
+	d, err := toDecimal(v)
+	if err != nil {
+		return decimal.Decimal{}, &MismatchedType{column: column, target: reflect.TypeOf(decimal.Decimal{}), value: v}
+	}
+
+	return d, nil
+}
+
+func ScaleDecimal(field string, d decimal.Decimal, scale int32) (int64, error) {
+	return scaleDecimal(field, d, scale)
+}
+
+func UnsignedValue(field string, v int64) (uint64, error) {
+	if v < 0 {
+		return 0, &NegativeUnsigned{field: field}
+	}
+
+	return uint64(v), nil
+}
+
+func TextValue(m encoding.TextMarshaler) (string, error) {
+	b, err := m.MarshalText()
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// DecodeValue decodes v into the value dst points to, it is used for the types
+// the typed decode helpers do not cover
+func DecodeValue(column string, dst any, v any) error {
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return &UnSupportedType{}
+	}
+
+	return setFieldValue(val.Elem(), column, v)
+}
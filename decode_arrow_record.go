@@ -0,0 +1,301 @@
+package influxqu
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+type arrowColumn struct {
+	column int
+	name   string
+	field  *planField // nil for a column decoded into the dynamic maps
+	elem   int        // element index of an expanded field
+}
+
+type arrowPlanKey struct {
+	t           reflect.Type
+	fingerprint string
+}
+
+// arrowPlan resolves the columns of schema onto the fields of t, the result is cached per schema
+func (q *influxQu) arrowPlan(t reflect.Type, schema *arrow.Schema) ([]arrowColumn, error) {
+	key := arrowPlanKey{t: t, fingerprint: schema.Fingerprint()}
+	if p, ok := q.arrowPlans.Load(key); ok {
+		return p.([]arrowColumn), nil
+	}
+
+	p, err := q.typePlan(t)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]*planField, len(p.fields))
+	for i := range p.fields {
+		if !p.fields[i].expand {
+			targets[sqlColumnNames.column(&p.fields[i])] = &p.fields[i]
+		}
+	}
+
+	plan := make([]arrowColumn, 0, len(targets))
+
+	for i, f := range schema.Fields() {
+		if pf, ok := targets[f.Name]; ok {
+			plan = append(plan, arrowColumn{column: i, name: f.Name, field: pf})
+		} else if pf, elem, ok := p.expandColumn(f.Name); ok {
+			plan = append(plan, arrowColumn{column: i, name: f.Name, field: pf, elem: elem})
+		} else if (p.tagMap != nil || p.fieldMap != nil) && !sqlColumnNames.reserved(f.Name) {
+			plan = append(plan, arrowColumn{column: i, name: f.Name})
+		}
+	}
+
+	q.arrowPlans.Store(key, plan)
+
+	return plan, nil
+}
+
+func arrowValue(arr arrow.Array, i int) interface{} {
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.Int8:
+		return a.Value(i)
+	case *array.Int16:
+		return a.Value(i)
+	case *array.Int32:
+		return a.Value(i)
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return a.Value(i)
+	case *array.Uint16:
+		return a.Value(i)
+	case *array.Uint32:
+		return a.Value(i)
+	case *array.Uint64:
+		return a.Value(i)
+	case *array.Float16:
+		return a.Value(i)
+	case *array.Float32:
+		return a.Value(i)
+	case *array.Float64:
+		return a.Value(i)
+	case *array.String:
+		return a.Value(i)
+	case *array.LargeString:
+		return a.Value(i)
+	case *array.Binary:
+		return a.Value(i)
+	case *array.Timestamp:
+		return a.Value(i).ToTime(a.DataType().(*arrow.TimestampType).Unit)
+	case *array.Dictionary:
+		return arrowValue(a.Dictionary(), a.GetValueIndex(i))
+	}
+
+	return arr.ValueStr(i)
+}
+
+// decodeArrowColumn fills one column into rows, common column and field type pairs are set directly,
+// prefix is trimmed from the measurement like setData does
+func decodeArrowColumn(arr arrow.Array, name string, rows []reflect.Value, pf *planField, prefix string) error {
+	index := pf.index
+	target := rows[0].Type().FieldByIndex(index).Type
+	kind := target.Kind()
+
+	if pf.unmarshaler || (pf.role == roleMeasurement && prefix != "") {
+		kind = reflect.Invalid
+		target = nil
+	}
+
+	switch a := arr.(type) {
+	case *array.Int64:
+		if kind == reflect.Int64 || kind == reflect.Int {
+			for r, row := range rows {
+				if !a.IsNull(r) {
+					fieldByIndexAlloc(row, index).SetInt(a.Value(r))
+				}
+			}
+
+			return nil
+		}
+	case *array.Uint64:
+		if kind == reflect.Uint64 || kind == reflect.Uint {
+			for r, row := range rows {
+				if !a.IsNull(r) {
+					fieldByIndexAlloc(row, index).SetUint(a.Value(r))
+				}
+			}
+
+			return nil
+		}
+	case *array.Float64:
+		if kind == reflect.Float64 {
+			for r, row := range rows {
+				if !a.IsNull(r) {
+					fieldByIndexAlloc(row, index).SetFloat(a.Value(r))
+				}
+			}
+
+			return nil
+		}
+	case *array.Boolean:
+		if kind == reflect.Bool {
+			for r, row := range rows {
+				if !a.IsNull(r) {
+					fieldByIndexAlloc(row, index).SetBool(a.Value(r))
+				}
+			}
+
+			return nil
+		}
+	case *array.String:
+		if kind == reflect.String {
+			for r, row := range rows {
+				if !a.IsNull(r) {
+					fieldByIndexAlloc(row, index).SetString(a.Value(r))
+				}
+			}
+
+			return nil
+		}
+	case *array.Dictionary:
+		if dict, ok := a.Dictionary().(*array.String); ok && kind == reflect.String {
+			for r, row := range rows {
+				if !a.IsNull(r) {
+					fieldByIndexAlloc(row, index).SetString(dict.Value(a.GetValueIndex(r)))
+				}
+			}
+
+			return nil
+		}
+	case *array.Timestamp:
+		if target == reflect.TypeOf(time.Time{}) {
+			unit := a.DataType().(*arrow.TimestampType).Unit
+			for r, row := range rows {
+				if !a.IsNull(r) {
+					fieldByIndexAlloc(row, index).Set(reflect.ValueOf(a.Value(r).ToTime(unit)))
+				}
+			}
+
+			return nil
+		}
+	}
+
+	for r, row := range rows {
+		if arr.IsNull(r) {
+			continue
+		}
+
+		v := arrowValue(arr, r)
+		if s, ok := v.(string); ok && pf.role == roleMeasurement {
+			v = strings.TrimPrefix(s, prefix)
+		}
+
+		if err := pf.setValue(fieldByIndexAlloc(row, index), name, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *influxQu) DecodeArrowRecord(rec arrow.RecordBatch, dst any) error {
+	if rec == nil {
+		return &UnSupportedType{}
+	}
+
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Slice {
+		return &UnSupportedType{}
+	}
+
+	slice := val.Elem()
+	elemType, elemKind := getTypeInfo(dst, slice)
+
+	if elemKind != reflect.Struct {
+		return &UnSupportedType{}
+	}
+
+	plan, err := q.arrowPlan(elemType, rec.Schema())
+	if err != nil {
+		return err
+	}
+
+	p, err := q.typePlan(elemType)
+	if err != nil {
+		return err
+	}
+
+	n := int(rec.NumRows())
+	if n == 0 {
+		return nil
+	}
+
+	isPtr := slice.Type().Elem().Kind() == reflect.Ptr
+	base := slice.Len()
+	grown := reflect.AppendSlice(slice, reflect.MakeSlice(slice.Type(), n, n))
+	rows := make([]reflect.Value, n)
+
+	for r := range rows {
+		elem := grown.Index(base + r)
+		if isPtr {
+			elem.Set(reflect.New(elemType))
+			elem = elem.Elem()
+		}
+
+		rows[r] = elem
+	}
+
+	for _, c := range plan {
+		if c.field == nil {
+			arr := rec.Column(c.column)
+			for r, row := range rows {
+				if !arr.IsNull(r) {
+					p.setDynamicValue(row, c.name, "", arrowValue(arr, r))
+				}
+			}
+
+			continue
+		}
+
+		if c.field.expand {
+			arr := rec.Column(c.column)
+			for r, row := range rows {
+				if arr.IsNull(r) {
+					continue
+				}
+
+				if err := c.field.setElem(row, c.name, c.elem, arrowValue(arr, r)); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if err := decodeArrowColumn(rec.Column(c.column), c.name, rows, c.field, q.measurementPrefix); err != nil {
+			return err
+		}
+	}
+
+	slice.Set(grown)
+
+	return nil
+}
+
+func (q *influxQu) DecodeArrowReader(reader array.RecordReader, dst any) error {
+	if reader == nil {
+		return &UnSupportedType{}
+	}
+
+	for reader.Next() {
+		if err := q.DecodeArrowRecord(reader.RecordBatch(), dst); err != nil {
+			return err
+		}
+	}
+
+	return reader.Err()
+}
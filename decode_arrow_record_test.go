@@ -0,0 +1,132 @@
+package influxqu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+func newArrowRecord(t testing.TB, rows int) arrow.RecordBatch {
+	t.Helper()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+		{Name: "t1", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}},
+		{Name: "f1", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "f2", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "f3", Type: arrow.FixedWidthTypes.Boolean},
+		{Name: "f4", Type: arrow.BinaryTypes.String},
+	}, nil)
+
+	b := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer b.Release()
+
+	for i := 0; i < rows; i++ {
+		b.Field(0).(*array.TimestampBuilder).Append(arrow.Timestamp(int64(i) * int64(time.Second)))
+
+		if err := b.Field(1).(*array.BinaryDictionaryBuilder).AppendString("t1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if i%2 == 0 {
+			b.Field(2).(*array.Int64Builder).Append(int64(i))
+		} else {
+			b.Field(2).(*array.Int64Builder).AppendNull()
+		}
+
+		b.Field(3).(*array.Float64Builder).Append(float64(i) / 2)
+		b.Field(4).(*array.BooleanBuilder).Append(i%2 == 0)
+		b.Field(5).(*array.StringBuilder).Append("s")
+	}
+
+	return b.NewRecordBatch()
+}
+
+func Test_DecodeArrowRecord(t *testing.T) {
+	type MyString string
+
+	type Field struct {
+		F4 MyString `influxqu:"field,f4"`
+	}
+
+	type Data struct {
+		*Field
+		T1        string    `influxqu:"tag,t1"`
+		F1        *int64    `influxqu:"field,f1"`
+		F2        float64   `influxqu:"field,f2"`
+		F3        bool      `influxqu:"field,f3"`
+		Timestamp time.Time `influxqu:"timestamp"`
+	}
+
+	rec := newArrowRecord(t, 3)
+	defer rec.Release()
+
+	g := NewinfluxQu()
+
+	data := []Data{{T1: "existing"}}
+	if e := g.DecodeArrowRecord(rec, &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if len(data) != 4 || data[0].T1 != "existing" {
+		t.Fatalf("rows are not expected, got: %+v", data)
+	}
+
+	for i, d := range data[1:] {
+		if d.T1 != "t1" || d.F2 != float64(i)/2 || d.F3 != (i%2 == 0) || d.Field == nil || d.F4 != "s" {
+			t.Errorf("row %d is not expected, got: %+v", i, d)
+		}
+
+		if (i%2 == 0) != (d.F1 != nil) || (d.F1 != nil && *d.F1 != int64(i)) {
+			t.Errorf("row %d nullable field is not expected, got: %v", i, d.F1)
+		}
+
+		if !d.Timestamp.Equal(time.Unix(int64(i), 0)) {
+			t.Errorf("row %d timestamp is not expected, got: %v", i, d.Timestamp)
+		}
+	}
+}
+
+func Test_DecodeArrowRecord_Mismatched_Type(t *testing.T) {
+	type Data struct {
+		F3 int `influxqu:"field,f3"`
+	}
+
+	rec := newArrowRecord(t, 1)
+	defer rec.Release()
+
+	g := NewinfluxQu()
+
+	var data []*Data
+	if e := g.DecodeArrowRecord(rec, &data); e == nil {
+		t.Error("expected an error for mismatched type")
+	}
+}
+
+func Benchmark_DecodeArrowRecord(b *testing.B) {
+	type Data struct {
+		T1        string    `influxqu:"tag,t1"`
+		F1        int64     `influxqu:"field,f1"`
+		F2        float64   `influxqu:"field,f2"`
+		F3        bool      `influxqu:"field,f3"`
+		F4        string    `influxqu:"field,f4"`
+		Timestamp time.Time `influxqu:"timestamp"`
+	}
+
+	rec := newArrowRecord(b, 1024)
+	defer rec.Release()
+
+	g := NewinfluxQu()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		data := make([]Data, 0, 1024)
+		if e := g.DecodeArrowRecord(rec, &data); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
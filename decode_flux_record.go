@@ -0,0 +1,128 @@
+package influxqu
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+)
+
+const (
+	fluxMeasurementColumn = "_measurement"
+	fluxTimeColumn        = "_time"
+	fluxFieldColumn       = "_field"
+	fluxValueColumn       = "_value"
+)
+
+type columnNames struct {
+	measurement string
+	timestamp   string
+	meta        []string // columns never decoded into the dynamic maps
+}
+
+var fluxColumnNames = columnNames{
+	measurement: fluxMeasurementColumn,
+	timestamp:   fluxTimeColumn,
+	meta:        []string{"result", "table", "_start", "_stop", fluxFieldColumn, fluxValueColumn},
+}
+
+func (n columnNames) reserved(column string) bool {
+	if column == n.measurement || column == n.timestamp {
+		return true
+	}
+
+	for _, m := range n.meta {
+		if column == m {
+			return true
+		}
+	}
+
+	return false
+}
+
+func fluxRecordColumns(rec *query.FluxRecord) map[string]interface{} {
+	values := rec.Values()
+	cols := make(map[string]interface{}, len(values)+1)
+
+	for k, v := range values {
+		cols[k] = v
+	}
+
+	// an unpivoted record carries its field as a _field/_value pair
+	if f, ok := values[fluxFieldColumn].(string); ok && f != "" {
+		cols[f] = values[fluxValueColumn]
+	}
+
+	return cols
+}
+
+func (n columnNames) column(pf *planField) string {
+	switch pf.role {
+	case roleMeasurement:
+		return n.measurement
+	case roleTimestamp:
+		return n.timestamp
+	}
+
+	return pf.name
+}
+
+func (q *influxQu) setData(cols map[string]interface{}, names columnNames, val reflect.Value, t reflect.Type) error {
+	p, err := q.typePlan(t)
+	if err != nil {
+		return err
+	}
+
+	for i := range p.fields {
+		pf := &p.fields[i]
+		if pf.expand {
+			continue
+		}
+
+		column := names.column(pf)
+
+		v, ok := cols[column]
+		if !ok || v == nil {
+			continue
+		}
+
+		if s, ok := v.(string); ok && pf.role == roleMeasurement {
+			v = strings.TrimPrefix(s, q.measurementPrefix)
+		}
+
+		if err := pf.setValue(fieldByIndexAlloc(val, pf.index), column, v); err != nil {
+			return err
+		}
+	}
+
+	if err := p.setExpandData(cols, val); err != nil {
+		return err
+	}
+
+	p.setDynamicData(cols, names, val)
+
+	return nil
+}
+
+func (q *influxQu) decodeColumns(cols map[string]interface{}, names columnNames, dst any) error {
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return &UnSupportedType{}
+	}
+
+	val = val.Elem()
+
+	return q.setData(cols, names, val, val.Type())
+}
+
+func (q *influxQu) DecodeFluxRecord(rec *query.FluxRecord, dst any) error {
+	if rec == nil {
+		return &UnSupportedType{}
+	}
+
+	if d, ok := dst.(FluxRecordDecoder); ok && q.measurementPrefix == "" && q.generatedFor(dst) {
+		return d.FromFluxRecord(rec)
+	}
+
+	return q.decodeColumns(fluxRecordColumns(rec), fluxColumnNames, dst)
+}
@@ -0,0 +1,215 @@
+package influxqu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+	"github.com/shopspring/decimal"
+)
+
+func Test_DecodeFluxRecord_Simple_Struct(t *testing.T) {
+	type Tag struct {
+		T2 *string `influxqu:"tag,t2"`
+	}
+
+	type Data struct {
+		Tag
+		Base      string          `influxqu:"measurement"`
+		T1        string          `influxqu:"tag,t1"`
+		F1        int             `influxqu:"field,f1"`
+		F2        bool            `influxqu:"field,f2"`
+		F3        decimal.Decimal `influxqu:"field,f3"`
+		Timestamp time.Time       `influxqu:"timestamp"`
+	}
+
+	now := time.Now()
+	rec := query.NewFluxRecord(0, map[string]interface{}{
+		"_measurement": "base",
+		"_time":        now,
+		"t1":           "t1",
+		"t2":           "t2",
+		"f1":           int64(1),
+		"f2":           true,
+		"f3":           1.35,
+	})
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeFluxRecord(rec, &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Base != "base" || data.T1 != "t1" || data.T2 == nil || *data.T2 != "t2" {
+		t.Errorf("measurement or tags are not expected, got: %+v", data)
+	}
+
+	if data.F1 != 1 || !data.F2 || !data.F3.Equal(decimal.NewFromFloat(1.35)) {
+		t.Errorf("fields are not expected, got: %+v", data)
+	}
+
+	if !data.Timestamp.Equal(now) {
+		t.Errorf("timestamp is not expected, got: %v, expected: %v", data.Timestamp, now)
+	}
+}
+
+func Test_DecodeFluxRecord_Field_Value(t *testing.T) {
+	type MyString string
+
+	type Data struct {
+		Base MyString `influxqu:"measurement"`
+		T1   int      `influxqu:"tag,t1"`
+		F1   *float64 `influxqu:"field,f1"`
+		F2   string   `influxqu:"field,f2"`
+	}
+
+	rec := query.NewFluxRecord(0, map[string]interface{}{
+		"_measurement": "base",
+		"_field":       "f1",
+		"_value":       1.5,
+		"t1":           "12",
+	})
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeFluxRecord(rec, &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Base != "base" || data.T1 != 12 {
+		t.Errorf("measurement or tags are not expected, got: %+v", data)
+	}
+
+	if data.F1 == nil || *data.F1 != 1.5 || data.F2 != "" {
+		t.Errorf("fields are not expected, got: %+v", data)
+	}
+}
+
+func Test_DecodeFluxRecord_Mismatched_Type(t *testing.T) {
+	type Data struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+	}
+
+	rec := query.NewFluxRecord(0, map[string]interface{}{
+		"_measurement": "base",
+		"f1":           true,
+	})
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeFluxRecord(rec, &data); e == nil {
+		t.Error("expected an error for mismatched type")
+	}
+
+	if e := g.DecodeFluxRecord(rec, data); e == nil {
+		t.Error("expected an error for non-pointer destination")
+	}
+}
+
+func Test_DecodeFluxRecord_Decimal_Modes(t *testing.T) {
+	type Data struct {
+		Base   string           `influxqu:"measurement"`
+		Amount decimal.Decimal  `influxqu:"field,amount,decimal=scaled:4"`
+		Total  *decimal.Decimal `influxqu:"field,total,decimal=string"`
+	}
+
+	g := NewinfluxQu()
+	total := decimal.RequireFromString("12345678901234567890.123456789")
+	data := Data{Base: "ledger", Amount: decimal.RequireFromString("-10.0125"), Total: &total}
+
+	p, e := g.GenerateInfluxPoint(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	values := map[string]interface{}{"_measurement": p.Name()}
+	for _, f := range p.FieldList() {
+		values[f.Key] = f.Value
+	}
+
+	var decoded Data
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, values), &decoded); e != nil {
+		t.Fatal(e)
+	}
+
+	if !decoded.Amount.Equal(data.Amount) || decoded.Total == nil || !decoded.Total.Equal(total) {
+		t.Errorf("decimals do not survive the round trip, got: %v %v", decoded.Amount, decoded.Total)
+	}
+}
+
+func Test_DecodeFluxRecord_Unsigned(t *testing.T) {
+	type Data struct {
+		Total   uint64 `influxqu:"field,total"`
+		Dropped int64  `influxqu:"field,dropped,type=uint"`
+	}
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{
+		"total":   uint64(1<<63 + 1),
+		"dropped": uint64(5),
+	}), &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Total != 1<<63+1 || data.Dropped != 5 {
+		t.Errorf("unsigned values are not expected, got: %+v", data)
+	}
+
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{"dropped": uint64(1 << 63)}), &data); e == nil {
+		t.Error("expected an error for a counter above the signed range")
+	}
+}
+
+func Test_DecodeFluxRecord_Timestamp_Options(t *testing.T) {
+	type Data struct {
+		At int64 `influxqu:"timestamp,unix=ms"`
+	}
+
+	type Layout struct {
+		At string `influxqu:"timestamp,layout=RFC1123"`
+	}
+
+	g := NewinfluxQu()
+	rec := query.NewFluxRecord(0, map[string]interface{}{"_time": time.UnixMilli(1700000000123).UTC()})
+
+	var data Data
+	if e := g.DecodeFluxRecord(rec, &data); e != nil || data.At != 1700000000123 {
+		t.Errorf("unix timestamp is not decoded, got: %d, %v", data.At, e)
+	}
+
+	var layout Layout
+	if e := g.DecodeFluxRecord(rec, &layout); e != nil || layout.At != "Tue, 14 Nov 2023 22:13:20 UTC" {
+		t.Errorf("layout timestamp is not decoded, got: %q, %v", layout.At, e)
+	}
+}
+
+func Test_DecodeFluxRecord_Inline_Struct(t *testing.T) {
+	type Reading struct {
+		Value float64 `influxqu:"field,value"`
+	}
+
+	type Sensor struct {
+		Humidity *Reading `influxqu:"inline,prefix=hum_"`
+	}
+
+	type Data struct {
+		Room *Sensor `influxqu:"inline,prefix=room_"`
+	}
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{"room_hum_value": 40.5}), &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Room == nil || data.Room.Humidity == nil || data.Room.Humidity.Value != 40.5 {
+		t.Errorf("inline field is not decoded, got: %+v", data.Room)
+	}
+}
@@ -0,0 +1,59 @@
+package influxqu
+
+import (
+	"reflect"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+)
+
+// decodeRows appends one element to the slice dst per row, decode fills the addressable element
+func decodeRows(dst any, next func() bool, decode func(elem reflect.Value) error) error {
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Slice {
+		return &UnSupportedType{}
+	}
+
+	slice := val.Elem()
+	elemType, elemKind := getTypeInfo(dst, slice)
+
+	if elemKind != reflect.Struct {
+		return &UnSupportedType{}
+	}
+
+	isPtr := slice.Type().Elem().Kind() == reflect.Ptr
+
+	for next() {
+		elem := reflect.New(elemType)
+		if err := decode(elem); err != nil {
+			return err
+		}
+
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+
+	return nil
+}
+
+func (q *influxQu) DecodeFluxTable(res *api.QueryTableResult, dst any) error {
+	if res == nil {
+		return &UnSupportedType{}
+	}
+
+	decode := func(elem reflect.Value) error {
+		if d, ok := elem.Interface().(FluxRecordDecoder); ok && q.measurementPrefix == "" && q.generatedFor(d) {
+			return d.FromFluxRecord(res.Record())
+		}
+
+		return q.setData(fluxRecordColumns(res.Record()), fluxColumnNames, elem.Elem(), elem.Elem().Type())
+	}
+
+	if err := decodeRows(dst, res.Next, decode); err != nil {
+		return err
+	}
+
+	return res.Err()
+}
@@ -0,0 +1,101 @@
+package influxqu
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+)
+
+func newQueryTableResult(csv string) *api.QueryTableResult {
+	return api.NewQueryTableResult(io.NopCloser(strings.NewReader(csv)))
+}
+
+func Test_DecodeFluxTable_Pivoted(t *testing.T) {
+	type Data struct {
+		Base      string    `influxqu:"measurement"`
+		T1        string    `influxqu:"tag,t1"`
+		F1        int       `influxqu:"field,f1"`
+		F2        *bool     `influxqu:"field,f2"`
+		Timestamp time.Time `influxqu:"timestamp"`
+	}
+
+	res := newQueryTableResult(`#datatype,string,long,dateTime:RFC3339,string,string,long,boolean
+#group,false,false,false,true,true,false,false
+#default,_result,,,,,,
+,result,table,_time,_measurement,t1,f1,f2
+,,0,2023-01-01T00:00:00Z,base,a,1,true
+,,0,2023-01-01T00:01:00Z,base,a,2,
+`)
+
+	g := NewinfluxQu()
+
+	var data []Data
+	if e := g.DecodeFluxTable(res, &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if len(data) != 2 {
+		t.Fatalf("rows are not expected, got: %d, expected: 2", len(data))
+	}
+
+	if data[0].Base != "base" || data[0].T1 != "a" || data[0].F1 != 1 || data[0].F2 == nil || !*data[0].F2 {
+		t.Errorf("first row is not expected, got: %+v", data[0])
+	}
+
+	if data[1].F1 != 2 || data[1].F2 != nil || !data[1].Timestamp.Equal(time.Date(2023, 1, 1, 0, 1, 0, 0, time.UTC)) {
+		t.Errorf("second row is not expected, got: %+v", data[1])
+	}
+}
+
+func Test_DecodeFluxTable_Pointer_Elements(t *testing.T) {
+	type Data struct {
+		Base string  `influxqu:"measurement"`
+		F1   float64 `influxqu:"field,f1"`
+	}
+
+	res := newQueryTableResult(`#datatype,string,long,string,double
+#group,false,false,true,false
+#default,_result,,,
+,result,table,_measurement,f1
+,,0,base,1.5
+`)
+
+	g := NewinfluxQu()
+
+	var data []*Data
+	if e := g.DecodeFluxTable(res, &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if len(data) != 1 || data[0].Base != "base" || data[0].F1 != 1.5 {
+		t.Errorf("rows are not expected, got: %+v", data)
+	}
+}
+
+func Test_DecodeFluxTable_Mismatched_Type(t *testing.T) {
+	type Data struct {
+		Base string `influxqu:"measurement"`
+		F1   bool   `influxqu:"field,f1"`
+	}
+
+	res := newQueryTableResult(`#datatype,string,long,string,double
+#group,false,false,true,false
+#default,_result,,,
+,result,table,_measurement,f1
+,,0,base,1.5
+`)
+
+	g := NewinfluxQu()
+
+	var data []Data
+	if e := g.DecodeFluxTable(res, &data); e == nil {
+		t.Error("expected an error for mismatched type")
+	}
+
+	if e := g.DecodeFluxTable(newQueryTableResult(""), data); e == nil {
+		t.Error("expected an error for non-pointer destination")
+	}
+}
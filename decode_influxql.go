@@ -0,0 +1,143 @@
+package influxqu
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	influxQLMeasurementColumn = "_measurement"
+	influxQLTimeColumn        = "time"
+)
+
+var influxQLColumnNames = columnNames{measurement: influxQLMeasurementColumn, timestamp: influxQLTimeColumn}
+
+type influxQLSeries struct {
+	Name    string            `json:"name"`
+	Tags    map[string]string `json:"tags"`
+	Columns []string          `json:"columns"`
+	Values  [][]any           `json:"values"`
+}
+
+type influxQLResponse struct {
+	Results []struct {
+		Series []influxQLSeries `json:"series"`
+		Error  string           `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+// influxQLValue converts a number of the response to int64, uint64 or float64, a numeric time
+// is read in the epoch precision the query was sent with
+func influxQLValue(column string, v any, epoch time.Duration) any {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+
+	if i, err := n.Int64(); err == nil {
+		if column == influxQLTimeColumn && epoch != 0 {
+			return influxQLTime(i, epoch)
+		}
+
+		return i
+	}
+
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u
+	}
+
+	f, _ := n.Float64()
+
+	return f
+}
+
+func influxQLTime(n int64, epoch time.Duration) time.Time {
+	switch epoch {
+	case time.Hour, time.Minute:
+		return time.Unix(n*int64(epoch/time.Second), 0).UTC()
+	}
+
+	return unixTime(n, epoch).UTC()
+}
+
+// influxQLRows flattens the series of the response into one column map per row, the series name is
+// the _measurement column and the series tags are columns too
+func influxQLRows(r io.Reader, epoch time.Duration) ([]map[string]any, error) {
+	var res influxQLResponse
+
+	d := json.NewDecoder(r)
+	d.UseNumber()
+
+	if err := d.Decode(&res); err != nil {
+		return nil, err
+	}
+
+	if res.Error != "" {
+		return nil, &InfluxQLError{message: res.Error}
+	}
+
+	var rows []map[string]any
+
+	for _, result := range res.Results {
+		if result.Error != "" {
+			return nil, &InfluxQLError{message: result.Error}
+		}
+
+		for _, s := range result.Series {
+			for _, values := range s.Values {
+				cols := make(map[string]any, len(s.Columns)+len(s.Tags)+1)
+				cols[influxQLMeasurementColumn] = s.Name
+
+				for k, v := range s.Tags {
+					cols[k] = v
+				}
+
+				for i, c := range s.Columns {
+					if i < len(values) {
+						cols[c] = influxQLValue(c, values[i], epoch)
+					}
+				}
+
+				rows = append(rows, cols)
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// DecodeInfluxQLResponse decodes the JSON body of an InfluxQL /query response into dst, a pointer
+// to a slice of structures, one element per row of every series. epoch is the precision of the epoch
+// parameter of the query, time.Hour down to time.Nanosecond, or 0 for the default RFC3339 times.
+func (q *influxQu) DecodeInfluxQLResponse(r io.Reader, epoch time.Duration, dst any) error {
+	if r == nil {
+		return &UnSupportedType{}
+	}
+
+	switch epoch {
+	case 0, time.Hour, time.Minute, time.Second, time.Millisecond, time.Microsecond, time.Nanosecond:
+	default:
+		return &UnSupportedPrecision{}
+	}
+
+	rows, err := influxQLRows(r, epoch)
+	if err != nil {
+		return err
+	}
+
+	i := -1
+	next := func() bool {
+		i++
+		return i < len(rows)
+	}
+
+	decode := func(elem reflect.Value) error {
+		return q.setData(rows[i], influxQLColumnNames, elem.Elem(), elem.Elem().Type())
+	}
+
+	return decodeRows(dst, next, decode)
+}
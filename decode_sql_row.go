@@ -0,0 +1,34 @@
+package influxqu
+
+import (
+	"reflect"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
+)
+
+const (
+	sqlMeasurementColumn = "iox::measurement"
+	sqlTimeColumn        = "time"
+)
+
+var sqlColumnNames = columnNames{measurement: sqlMeasurementColumn, timestamp: sqlTimeColumn}
+
+func (q *influxQu) DecodeSQLRow(row map[string]any, dst any) error {
+	return q.decodeColumns(row, sqlColumnNames, dst)
+}
+
+func (q *influxQu) DecodeSQLRows(it *influxdb3.QueryIterator, dst any) error {
+	if it == nil {
+		return &UnSupportedType{}
+	}
+
+	decode := func(elem reflect.Value) error {
+		return q.setData(it.Value(), sqlColumnNames, elem.Elem(), elem.Elem().Type())
+	}
+
+	if err := decodeRows(dst, it.Next, decode); err != nil {
+		return err
+	}
+
+	return it.Err()
+}
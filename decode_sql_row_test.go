@@ -0,0 +1,80 @@
+package influxqu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/shopspring/decimal"
+)
+
+func Test_DecodeSQLRow(t *testing.T) {
+	type MyString string
+
+	type Data struct {
+		Base      string          `influxqu:"measurement"`
+		T1        MyString        `influxqu:"tag,t1"`
+		T2        *string         `influxqu:"tag,t2"`
+		F1        int             `influxqu:"field,f1"`
+		F2        uint64          `influxqu:"field,f2"`
+		F3        *float64        `influxqu:"field,f3"`
+		F4        bool            `influxqu:"field,f4"`
+		F5        decimal.Decimal `influxqu:"field,f5"`
+		F6        int32           `influxqu:"field,f6"`
+		Timestamp time.Time       `influxqu:"timestamp"`
+	}
+
+	now := time.Unix(0, time.Now().UnixNano())
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeSQLRow(map[string]any{
+		"iox::measurement": "base",
+		"time":             arrow.Timestamp(now.UnixNano()),
+		"t1":               "t1",
+		"t2":               "t2",
+		"f1":               int64(1),
+		"f2":               uint64(1 << 63),
+		"f3":               1.5,
+		"f4":               true,
+		"f5":               "1.35",
+		"f6":               nil,
+	}, &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Base != "base" || data.T1 != "t1" || data.T2 == nil || *data.T2 != "t2" {
+		t.Errorf("measurement or tags are not expected, got: %+v", data)
+	}
+
+	if data.F1 != 1 || data.F2 != 1<<63 || data.F3 == nil || *data.F3 != 1.5 || !data.F4 || data.F6 != 0 {
+		t.Errorf("fields are not expected, got: %+v", data)
+	}
+
+	if !data.F5.Equal(decimal.RequireFromString("1.35")) {
+		t.Errorf("decimal field is not expected, got: %v", data.F5)
+	}
+
+	if !data.Timestamp.Equal(now) {
+		t.Errorf("timestamp is not expected, got: %v, expected: %v", data.Timestamp, now)
+	}
+}
+
+func Test_DecodeSQLRow_Overflow(t *testing.T) {
+	type Data struct {
+		F1 int8 `influxqu:"field,f1"`
+		F2 int  `influxqu:"field,f2"`
+	}
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeSQLRow(map[string]any{"f1": int64(1024)}, &data); e == nil {
+		t.Error("expected an error for overflowed value")
+	}
+
+	if e := g.DecodeSQLRow(map[string]any{"f2": uint64(1 << 63)}, &data); e == nil {
+		t.Error("expected an error for overflowed value")
+	}
+}
@@ -0,0 +1,154 @@
+package influxqu
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	return keys
+}
+
+// dynamicFieldValue converts a fields map entry as processFields converts a static field
+func dynamicFieldValue(v reflect.Value, omitempty bool) (interface{}, bool) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+
+		v = v.Elem()
+	}
+
+	if omitempty && v.IsZero() {
+		return nil, false
+	}
+
+	switch {
+	case isDecimalType(v.Type()):
+		return v.Interface().(decimal.Decimal).InexactFloat64(), true
+	case isUintKind(v.Kind()):
+		return v.Uint(), true
+	}
+
+	return basicFieldValue(v), true
+}
+
+// emitsField reports whether name is a static, inlined or expanded field name of p, or already in fields
+func (p *typePlan) emitsField(name string, fields map[string]interface{}) bool {
+	if _, ok := fields[name]; ok {
+		return true
+	}
+
+	if _, ok := p.fieldNames[name]; ok {
+		return true
+	}
+
+	_, _, ok := p.expandColumn(name)
+
+	return ok
+}
+
+// getDynamicData adds the entries of the tags and fields maps, they must not collide with the other names
+func (p *typePlan) getDynamicData(val reflect.Value, tags map[string]string, fields map[string]interface{}) error {
+	if p.tagMap != nil {
+		if m, ok := fieldByIndex(val, p.tagMap.index); ok && !m.IsNil() {
+			for _, k := range sortedMapKeys(m) {
+				name := k.String()
+				_, isTag := tags[name]
+				_, isDeclared := p.tagNames[name]
+
+				if isTag || isDeclared {
+					return &DuplicatedTag{tag: name}
+				}
+
+				if s := m.MapIndex(k).String(); !p.tagMap.omitempty || s != "" {
+					tags[name] = s
+				}
+			}
+		}
+	}
+
+	if p.fieldMap != nil {
+		if m, ok := fieldByIndex(val, p.fieldMap.index); ok && !m.IsNil() {
+			for _, k := range sortedMapKeys(m) {
+				name := k.String()
+				if p.emitsField(name, fields) {
+					return &DuplicatedField{field: name}
+				}
+
+				if v, ok := dynamicFieldValue(m.MapIndex(k), p.fieldMap.omitempty); ok {
+					fields[name] = v
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// setMapEntry converts v to the element type of the map member and stores it under key,
+// it reports false when v does not convert
+func setMapEntry(val reflect.Value, pf *planField, key string, v interface{}) bool {
+	m := fieldByIndexAlloc(val, pf.index)
+
+	elem := reflect.New(m.Type().Elem()).Elem()
+	if err := setFieldValue(elem, key, v); err != nil {
+		return false
+	}
+
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+
+	m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), elem)
+
+	return true
+}
+
+// setDynamicValue puts an unmatched column into the tags map when it holds a string and is not
+// the field of an unpivoted record, otherwise into the fields map
+func (p *typePlan) setDynamicValue(val reflect.Value, column, field string, v interface{}) {
+	if _, ok := v.(string); ok && p.tagMap != nil && column != field {
+		if setMapEntry(val, p.tagMap, column, v) {
+			return
+		}
+	}
+
+	if p.fieldMap != nil {
+		setMapEntry(val, p.fieldMap, column, v)
+	}
+}
+
+// setDynamicData decodes the columns no static tag or field maps to
+func (p *typePlan) setDynamicData(cols map[string]interface{}, names columnNames, val reflect.Value) {
+	if p.tagMap == nil && p.fieldMap == nil {
+		return
+	}
+
+	field, _ := cols[fluxFieldColumn].(string)
+
+	for column, v := range cols {
+		if v == nil || names.reserved(column) {
+			continue
+		}
+
+		if _, ok := p.tagNames[column]; ok {
+			continue
+		}
+
+		if _, ok := p.fieldNames[column]; ok {
+			continue
+		}
+
+		if _, _, ok := p.expandColumn(column); ok {
+			continue
+		}
+
+		p.setDynamicValue(val, column, field, v)
+	}
+}
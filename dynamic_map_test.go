@@ -0,0 +1,139 @@
+package influxqu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+	"github.com/shopspring/decimal"
+)
+
+func Test_GenerateInfluxPoint_Dynamic_Maps(t *testing.T) {
+	type Reading struct {
+		Value float64 `influxqu:"field,value"`
+	}
+
+	type Data struct {
+		Base   string                 `influxqu:"measurement"`
+		Host   string                 `influxqu:"tag,host"`
+		Usage  float64                `influxqu:"field,usage"`
+		Labels map[string]string      `influxqu:"tags,omitempty"`
+		Values map[string]interface{} `influxqu:"fields,omitempty"`
+		At     time.Time              `influxqu:"timestamp"`
+	}
+
+	g := NewinfluxQu()
+	data := Data{
+		Base:   "base",
+		Host:   "h",
+		Usage:  0.5,
+		Labels: map[string]string{"zone": "z1", "app": "api", "empty": ""},
+		Values: map[string]interface{}{
+			"count": uint32(3),
+			"price": decimal.NewFromFloat(1.5),
+			"zero":  0,
+			"none":  nil,
+		},
+		At: time.Unix(1, 0),
+	}
+
+	b, e := g.MarshalLineProtocol(&data, time.Second)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if expected := "base,app=api,host=h,zone=z1 count=3u,price=1.5,usage=0.5 1\n"; string(b) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected: %q", b, expected)
+	}
+
+	data.Labels["host"] = "other"
+	if _, e := g.GenerateInfluxPoint(&data); e == nil || e.Error() != "duplicated tag host" {
+		t.Errorf("expected a duplicated tag error, got: %v", e)
+	}
+
+	delete(data.Labels, "host")
+	data.Values["usage"] = 1.0
+
+	if _, e := g.GenerateInfluxPoint(&data); e == nil || e.Error() != "duplicated field usage" {
+		t.Errorf("expected a duplicated field error, got: %v", e)
+	}
+
+	type Expanded struct {
+		Base   string             `influxqu:"measurement"`
+		Cores  []float64          `influxqu:"field,core,expand"`
+		Temp   Reading            `influxqu:"inline,prefix=temp_"`
+		Values map[string]float64 `influxqu:"fields"`
+	}
+
+	for _, key := range []string{"core_0", "core_7", "temp_value"} {
+		expanded := Expanded{Base: "base", Cores: []float64{1}, Values: map[string]float64{key: 2}}
+		if _, e := g.GenerateInfluxPoint(&expanded); e == nil || e.Error() != "duplicated field "+key {
+			t.Errorf("expected a duplicated field error for %s, got: %v", key, e)
+		}
+	}
+
+	type BadTags struct {
+		Labels map[string]int `influxqu:"tags"`
+	}
+
+	if _, e := g.GenerateInfluxPoint(&BadTags{}); e == nil {
+		t.Error("expected an error for a tags map with non string values")
+	}
+}
+
+func Test_Decode_Dynamic_Maps(t *testing.T) {
+	type Data struct {
+		Base   string             `influxqu:"measurement"`
+		Host   string             `influxqu:"tag,host"`
+		Labels map[string]string  `influxqu:"tags"`
+		Values map[string]float64 `influxqu:"fields"`
+	}
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{
+		"result":       "_result",
+		"table":        int64(0),
+		"_measurement": "base",
+		"host":         "h",
+		"zone":         "z1",
+		"usage":        0.5,
+		"count":        int64(3),
+	}), &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if len(data.Labels) != 1 || data.Labels["zone"] != "z1" {
+		t.Errorf("tags are not expected, got: %v", data.Labels)
+	}
+
+	if len(data.Values) != 2 || data.Values["usage"] != 0.5 || data.Values["count"] != 3 {
+		t.Errorf("fields are not expected, got: %v", data.Values)
+	}
+
+	type Fields struct {
+		Values map[string]interface{} `influxqu:"fields"`
+	}
+
+	var fields Fields
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{
+		"_field": "state",
+		"_value": "on",
+	}), &fields); e != nil {
+		t.Fatal(e)
+	}
+
+	if fields.Values["state"] != "on" {
+		t.Errorf("unpivoted field is not decoded into the fields map, got: %v", fields.Values)
+	}
+
+	var rows []Data
+	if e := g.DecodeArrowRecord(newArrowRecord(t, 2), &rows); e != nil {
+		t.Fatal(e)
+	}
+
+	if rows[0].Labels["t1"] != "t1" || rows[0].Labels["f4"] != "s" || rows[1].Values["f2"] != 0.5 {
+		t.Errorf("arrow columns are not decoded into the maps, got: %+v", rows)
+	}
+}
@@ -1,23 +1,69 @@
 package influxqu
 
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The sentinels below match any error of their type with errors.Is, the fields of the error are ignored.
+var (
+	ErrUnSupportedType       error = &UnSupportedType{}
+	ErrDuplicatedMeasurement error = &DuplicatedMeasurement{}
+	ErrDuplicatedTimestamp   error = &DuplicatedTimestamp{}
+	ErrDuplicatedTag         error = &DuplicatedTag{}
+	ErrDuplicatedField       error = &DuplicatedField{}
+	ErrNoTagName             error = &NoTagName{}
+	ErrNoFieldName           error = &NoFieldName{}
+	ErrDuplicatedKey         error = &DuplicatedKey{}
+	ErrUnSupportedTag        error = &UnSupportedTag{}
+	ErrNoValidMeasurement    error = &NoValidMeasurement{}
+	ErrNoValidField          error = &NoValidField{}
+	ErrMismatchedType        error = &MismatchedType{}
+	ErrUnSupportedPrecision  error = &UnSupportedPrecision{}
+	ErrInexactDecimal        error = &InexactDecimal{}
+	ErrNegativeUnsigned      error = &NegativeUnsigned{}
+	ErrInvalidFluxTime       error = &InvalidFluxTime{}
+	ErrInvalidQueryArgument  error = &InvalidQueryArgument{}
+	ErrInvalidTimeRange      error = &InvalidTimeRange{}
+	ErrInfluxQL              error = &InfluxQLError{}
+	ErrUnsupportedFieldType  error = &UnsupportedFieldType{}
+)
+
 type UnSupportedType struct{}
 
 func (e *UnSupportedType) Error() string {
 	return "unsupported type"
 }
 
+func (e *UnSupportedType) Is(target error) bool {
+	_, ok := target.(*UnSupportedType)
+	return ok
+}
+
 type DuplicatedMeasurement struct{}
 
 func (e *DuplicatedMeasurement) Error() string {
 	return "duplicated measurement"
 }
 
+func (e *DuplicatedMeasurement) Is(target error) bool {
+	_, ok := target.(*DuplicatedMeasurement)
+	return ok
+}
+
 type DuplicatedTimestamp struct{}
 
 func (e *DuplicatedTimestamp) Error() string {
 	return "duplicated timestamp"
 }
 
+func (e *DuplicatedTimestamp) Is(target error) bool {
+	_, ok := target.(*DuplicatedTimestamp)
+	return ok
+}
+
 type DuplicatedTag struct {
 	tag string
 }
@@ -26,6 +72,11 @@ func (e *DuplicatedTag) Error() string {
 	return "duplicated tag " + e.tag
 }
 
+func (e *DuplicatedTag) Is(target error) bool {
+	_, ok := target.(*DuplicatedTag)
+	return ok
+}
+
 type DuplicatedField struct {
 	field string
 }
@@ -34,38 +85,268 @@ func (e *DuplicatedField) Error() string {
 	return "duplicated field " + e.field
 }
 
+func (e *DuplicatedField) Is(target error) bool {
+	_, ok := target.(*DuplicatedField)
+	return ok
+}
+
 type NoTagName struct{}
 
 func (e *NoTagName) Error() string {
 	return "no tag name"
 }
 
+func (e *NoTagName) Is(target error) bool {
+	_, ok := target.(*NoTagName)
+	return ok
+}
+
 type NoFieldName struct{}
 
 func (e *NoFieldName) Error() string {
 	return "no field name"
 }
 
+func (e *NoFieldName) Is(target error) bool {
+	_, ok := target.(*NoFieldName)
+	return ok
+}
+
 type DuplicatedKey struct{}
 
 func (e *DuplicatedKey) Error() string {
 	return "duplicated key"
 }
 
+func (e *DuplicatedKey) Is(target error) bool {
+	_, ok := target.(*DuplicatedKey)
+	return ok
+}
+
 type UnSupportedTag struct{}
 
 func (e *UnSupportedTag) Error() string {
 	return "unsupported tag"
 }
 
+func (e *UnSupportedTag) Is(target error) bool {
+	_, ok := target.(*UnSupportedTag)
+	return ok
+}
+
 type NoValidMeasurement struct{}
 
 func (e *NoValidMeasurement) Error() string {
 	return "no valid measurement"
 }
 
+func (e *NoValidMeasurement) Is(target error) bool {
+	_, ok := target.(*NoValidMeasurement)
+	return ok
+}
+
 type NoValidField struct{}
 
 func (e *NoValidField) Error() string {
 	return "no valid field"
 }
+
+func (e *NoValidField) Is(target error) bool {
+	_, ok := target.(*NoValidField)
+	return ok
+}
+
+type MismatchedType struct {
+	column string
+	target reflect.Type
+	value  interface{}
+}
+
+func (e *MismatchedType) Error() string {
+	return fmt.Sprintf("column %s of type %T can not be decoded into %s", e.column, e.value, e.target)
+}
+
+func (e *MismatchedType) Is(target error) bool {
+	_, ok := target.(*MismatchedType)
+	return ok
+}
+
+type ElementError struct {
+	index int
+	err   error
+}
+
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("element %d: %s", e.index, e.err)
+}
+
+func (e *ElementError) Index() int {
+	return e.index
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.err
+}
+
+type UnSupportedPrecision struct{}
+
+func (e *UnSupportedPrecision) Error() string {
+	return "unsupported precision"
+}
+
+func (e *UnSupportedPrecision) Is(target error) bool {
+	_, ok := target.(*UnSupportedPrecision)
+	return ok
+}
+
+type InexactDecimal struct {
+	field string
+}
+
+func (e *InexactDecimal) Error() string {
+	return "decimal value of field " + e.field + " can not be scaled to an integer exactly"
+}
+
+func (e *InexactDecimal) Is(target error) bool {
+	_, ok := target.(*InexactDecimal)
+	return ok
+}
+
+type NegativeUnsigned struct {
+	field string
+}
+
+func (e *NegativeUnsigned) Error() string {
+	return "negative value of field " + e.field + " can not be written as an unsigned integer"
+}
+
+func (e *NegativeUnsigned) Is(target error) bool {
+	_, ok := target.(*NegativeUnsigned)
+	return ok
+}
+
+type InvalidFluxTime struct {
+	expr string
+}
+
+func (e *InvalidFluxTime) Error() string {
+	return "invalid flux time " + strconv.Quote(e.expr)
+}
+
+func (e *InvalidFluxTime) Is(target error) bool {
+	_, ok := target.(*InvalidFluxTime)
+	return ok
+}
+
+type InvalidQueryArgument struct {
+	name  string
+	value string
+}
+
+func (e *InvalidQueryArgument) Error() string {
+	return "invalid query argument " + e.name + ": " + strconv.Quote(e.value)
+}
+
+func (e *InvalidQueryArgument) Is(target error) bool {
+	_, ok := target.(*InvalidQueryArgument)
+	return ok
+}
+
+type InvalidTimeRange struct{}
+
+func (e *InvalidTimeRange) Error() string {
+	return "start of the time range is not before its stop"
+}
+
+func (e *InvalidTimeRange) Is(target error) bool {
+	_, ok := target.(*InvalidTimeRange)
+	return ok
+}
+
+// InfluxQLError is an error reported in the response of an InfluxQL query
+type InfluxQLError struct {
+	message string
+}
+
+func (e *InfluxQLError) Error() string {
+	return "influxql query failed: " + e.message
+}
+
+func (e *InfluxQLError) Is(target error) bool {
+	_, ok := target.(*InfluxQLError)
+	return ok
+}
+
+// UnsupportedFieldType is a Go type the encoders can not write in the role of its field,
+// it also matches ErrUnSupportedType, the error encoding a value of the type returns
+type UnsupportedFieldType struct {
+	role string
+	typ  reflect.Type
+}
+
+func (e *UnsupportedFieldType) Error() string {
+	return fmt.Sprintf("unsupported %s type %s", e.role, e.typ)
+}
+
+func (e *UnsupportedFieldType) Is(target error) bool {
+	switch target.(type) {
+	case *UnsupportedFieldType, *UnSupportedType:
+		return true
+	}
+
+	return false
+}
+
+// FieldError is a problem with the struct tag of a field, path is the Go path of the field like Data.Tag.T2
+type FieldError struct {
+	path string
+	tag  string
+	err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.tag == "" {
+		return e.path + ": " + e.err.Error()
+	}
+
+	return fmt.Sprintf("%s `%s`: %s", e.path, e.tag, e.err)
+}
+
+func (e *FieldError) Path() string {
+	return e.path
+}
+
+func (e *FieldError) Tag() string {
+	return e.tag
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.err
+}
+
+// SchemaError holds every problem Validate found in a type
+type SchemaError struct {
+	errs []*FieldError
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (e *SchemaError) Errors() []*FieldError {
+	return e.errs
+}
+
+func (e *SchemaError) Unwrap() []error {
+	errs := make([]error, len(e.errs))
+	for i, err := range e.errs {
+		errs[i] = err
+	}
+
+	return errs
+}
@@ -0,0 +1,150 @@
+package influxqu
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxExpandLen bounds the elements decoded into an expanded slice, so a column like core_50000000
+// does not allocate a huge slice. Columns past the bound, or past the length of an array, are left
+// to the dynamic fields map.
+const maxExpandLen = 1024
+
+// parseExpandOption parses expand or expand=<format>, the format holds %d for the index and optionally %s for the field name
+func parseExpandOption(pf *planField, format string, hasFormat bool) error {
+	if pf.role != roleField || (pf.kind != reflect.Slice && pf.kind != reflect.Array) {
+		return &UnSupportedTag{}
+	}
+
+	if !hasFormat {
+		format = defaultExpandFormat
+	}
+
+	if strings.Count(format, "%d") != 1 || strings.Count(format, "%s") > 1 ||
+		strings.Count(format, "%") != strings.Count(format, "%d")+strings.Count(format, "%s") {
+		return &UnSupportedTag{}
+	}
+
+	pf.expand = true
+	pf.expandPrefix, pf.expandSuffix, _ = strings.Cut(strings.Replace(format, "%s", pf.name, 1), "%d")
+
+	return nil
+}
+
+func (pf *planField) expandName(i int) string {
+	return pf.expandPrefix + strconv.Itoa(i) + pf.expandSuffix
+}
+
+// expandIndex returns the element index encoded in column, it must be below pf.expandLen
+func (pf *planField) expandIndex(column string) (int, bool) {
+	if len(column) <= len(pf.expandPrefix)+len(pf.expandSuffix) ||
+		!strings.HasPrefix(column, pf.expandPrefix) || !strings.HasSuffix(column, pf.expandSuffix) {
+		return 0, false
+	}
+
+	digits := column[len(pf.expandPrefix) : len(column)-len(pf.expandSuffix)]
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, false
+		}
+	}
+
+	i, err := strconv.Atoi(digits)
+
+	return i, err == nil && i < pf.expandLen
+}
+
+// expandColumn finds the expanded field and element index column decodes into
+func (p *typePlan) expandColumn(column string) (*planField, int, bool) {
+	for i := range p.fields {
+		pf := &p.fields[i]
+		if !pf.expand {
+			continue
+		}
+
+		if idx, ok := pf.expandIndex(column); ok {
+			return pf, idx, true
+		}
+	}
+
+	return nil, 0, false
+}
+
+// expandFields writes the elements of an expanded field, nil elements are left out
+func (p *typePlan) expandFields(pf *planField, fields map[string]interface{}, f reflect.Value) error {
+	if pf.isPtr {
+		if f.IsNil() {
+			return nil
+		}
+
+		f = f.Elem()
+	}
+
+	for i := 0; i < f.Len(); i++ {
+		name := pf.expandName(i)
+		_, isField := fields[name]
+		_, isDeclared := p.fieldNames[name]
+
+		if isField || isDeclared {
+			return &DuplicatedField{field: name}
+		}
+
+		if v, ok := dynamicFieldValue(f.Index(i), pf.omitempty); ok {
+			fields[name] = v
+		}
+	}
+
+	return nil
+}
+
+// expandElem returns element i of the slice or array f, growing a slice as needed
+func expandElem(f reflect.Value, i int) (reflect.Value, bool) {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+
+		f = f.Elem()
+	}
+
+	if f.Kind() == reflect.Slice && f.Len() <= i {
+		f.Set(reflect.AppendSlice(f, reflect.MakeSlice(f.Type(), i+1-f.Len(), i+1-f.Len())))
+	}
+
+	if i >= f.Len() {
+		return reflect.Value{}, false
+	}
+
+	return f.Index(i), true
+}
+
+func (pf *planField) setElem(val reflect.Value, column string, i int, v interface{}) error {
+	elem, ok := expandElem(fieldByIndexAlloc(val, pf.index), i)
+	if !ok {
+		return &MismatchedType{column: column, target: fieldByIndexAlloc(val, pf.index).Type(), value: v}
+	}
+
+	return setFieldValue(elem, column, v)
+}
+
+// setExpandData decodes the columns of the expanded fields
+func (p *typePlan) setExpandData(cols map[string]interface{}, val reflect.Value) error {
+	if !p.hasExpand {
+		return nil
+	}
+
+	for column, v := range cols {
+		if v == nil {
+			continue
+		}
+
+		if pf, i, ok := p.expandColumn(column); ok {
+			if err := pf.setElem(val, column, i, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package influxqu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+)
+
+func Test_GenerateInfluxPoint_Expand(t *testing.T) {
+	type Data struct {
+		Base    string      `influxqu:"measurement"`
+		Cores   [4]float64  `influxqu:"field,core,expand,omitempty"`
+		Buckets []*uint32   `influxqu:"field,p,expand=latency_%s%d"`
+		Spare   *[2]float64 `influxqu:"field,spare,expand"`
+		At      time.Time   `influxqu:"timestamp"`
+	}
+
+	one, two := uint32(1), uint32(2)
+
+	g := NewinfluxQu()
+	data := Data{
+		Base:    "base",
+		Cores:   [4]float64{0.5, 0, 0.25, 1},
+		Buckets: []*uint32{&one, nil, &two},
+		At:      time.Unix(1, 0),
+	}
+
+	b, e := g.MarshalLineProtocol(&data, time.Second)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	expected := "base core_0=0.5,core_2=0.25,core_3=1,latency_p0=1u,latency_p2=2u 1\n"
+	if string(b) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected: %q", b, expected)
+	}
+
+	type Collision struct {
+		Base  string    `influxqu:"measurement"`
+		Total int       `influxqu:"field,core_1"`
+		Cores []float64 `influxqu:"field,core,expand"`
+	}
+
+	if _, e := g.GenerateInfluxPoint(&Collision{Base: "base", Cores: []float64{1, 2}}); e == nil || e.Error() != "duplicated field core_1" {
+		t.Errorf("expected a duplicated field error, got: %v", e)
+	}
+}
+
+func Test_Decode_Expand(t *testing.T) {
+	type Data struct {
+		Base    string             `influxqu:"measurement"`
+		Cores   [2]float64         `influxqu:"field,core,expand"`
+		Buckets []int              `influxqu:"field,p,expand=latency_%s%d"`
+		Values  map[string]float64 `influxqu:"fields"`
+	}
+
+	g := NewinfluxQu()
+
+	var data Data
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{
+		"_measurement": "base",
+		"core_1":       0.5,
+		"core_x":       1.5,
+		"latency_p2":   int64(7),
+		"latency_p0":   int64(3),
+	}), &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Cores != [2]float64{0, 0.5} || len(data.Buckets) != 3 || data.Buckets[0] != 3 || data.Buckets[2] != 7 {
+		t.Errorf("expanded fields are not decoded, got: %+v", data)
+	}
+
+	if len(data.Values) != 1 || data.Values["core_x"] != 1.5 {
+		t.Errorf("fields are not expected, got: %v", data.Values)
+	}
+
+	var bounded Data
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{
+		"core_2":            0.5,
+		"latency_p50000000": int64(1),
+		"latency_p1":        int64(2),
+	}), &bounded); e != nil {
+		t.Fatal(e)
+	}
+
+	if len(bounded.Buckets) != 2 || bounded.Buckets[1] != 2 {
+		t.Errorf("expanded slice is not bounded, got: %d elements", len(bounded.Buckets))
+	}
+
+	if len(bounded.Values) != 2 || bounded.Values["core_2"] != 0.5 || bounded.Values["latency_p50000000"] != 1 {
+		t.Errorf("columns out of range are not decoded into the fields map, got: %v", bounded.Values)
+	}
+
+	type Row struct {
+		Values []interface{} `influxqu:"field,f,expand=f%d"`
+	}
+
+	var rows []Row
+	if e := g.DecodeArrowRecord(newArrowRecord(t, 2), &rows); e != nil {
+		t.Fatal(e)
+	}
+
+	if len(rows[0].Values) != 5 || rows[0].Values[1] != int64(0) || rows[1].Values[2] != 0.5 || rows[1].Values[4] != "s" {
+		t.Errorf("arrow columns are not decoded into the slice, got: %+v", rows)
+	}
+}
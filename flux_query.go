@@ -0,0 +1,168 @@
+package influxqu
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// fluxStage renders one stage of a FluxQuery, lit renders the values that come from the caller
+type fluxStage func(lit fluxLiteral) string
+
+// FluxQuery builds a Flux query seeded with the filters of a tagged structure, the stages are
+// added in the order the methods are called and the first invalid argument is returned by Build
+type FluxQuery struct {
+	q      *influxQu
+	bucket string
+	start  string
+	stop   string
+	filter any
+	stages []fluxStage
+	err    error
+}
+
+func (q *influxQu) NewFluxQuery(bucket string, filter any) *FluxQuery {
+	return &FluxQuery{q: q, bucket: bucket, filter: filter}
+}
+
+func (b *FluxQuery) add(stage fluxStage) *FluxQuery {
+	b.stages = append(b.stages, stage)
+	return b
+}
+
+func (b *FluxQuery) fail(name, value string) *FluxQuery {
+	if b.err == nil {
+		b.err = &InvalidQueryArgument{name: name, value: value}
+	}
+
+	return b
+}
+
+// fluxDuration writes d as a Flux duration literal, 90s is 1m30s
+func fluxDuration(d time.Duration) string {
+	units := []durationUnit{
+		{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"},
+		{time.Millisecond, "ms"}, {time.Microsecond, "us"}, {time.Nanosecond, "ns"},
+	}
+
+	var sb strings.Builder
+
+	for _, u := range units {
+		if n := d / u.unit; n > 0 {
+			sb.WriteString(strconv.FormatInt(int64(n), 10))
+			sb.WriteString(u.name)
+			d -= n * u.unit
+		}
+	}
+
+	return sb.String()
+}
+
+func fluxStrings(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = fluxString(c)
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func (b *FluxQuery) Range(rng TimeRange) *FluxQuery {
+	if err := rng.Validate(); err != nil && b.err == nil {
+		b.err = err
+	}
+
+	b.start, b.stop = fluxBound(rng.start), fluxBound(rng.stop)
+
+	return b
+}
+
+// Filter keeps the rows whose column equals value
+func (b *FluxQuery) Filter(column, value string) *FluxQuery {
+	return b.add(func(lit fluxLiteral) string {
+		return "filter(fn: (r) => r[" + fluxString(column) + "] == " + lit("filter", value) + ")"
+	})
+}
+
+// AggregateWindow aggregates every window with fn, a Flux function name like mean or last
+func (b *FluxQuery) AggregateWindow(every time.Duration, fn string, createEmpty bool) *FluxQuery {
+	if every <= 0 {
+		return b.fail("every", every.String())
+	}
+
+	if !identifierPattern.MatchString(fn) {
+		return b.fail("fn", fn)
+	}
+
+	return b.add(func(fluxLiteral) string {
+		return "aggregateWindow(every: " + fluxDuration(every) + ", fn: " + fn + ", createEmpty: " + strconv.FormatBool(createEmpty) + ")"
+	})
+}
+
+// Pivot turns the fields into columns, the rows can then be decoded by DecodeFluxTable
+func (b *FluxQuery) Pivot() *FluxQuery {
+	return b.add(func(fluxLiteral) string { return fluxPivot })
+}
+
+func (b *FluxQuery) Group(columns ...string) *FluxQuery {
+	return b.add(func(fluxLiteral) string { return "group(columns: " + fluxStrings(columns) + ")" })
+}
+
+func (b *FluxQuery) Sort(desc bool, columns ...string) *FluxQuery {
+	return b.add(func(fluxLiteral) string {
+		return "sort(columns: " + fluxStrings(columns) + ", desc: " + strconv.FormatBool(desc) + ")"
+	})
+}
+
+func (b *FluxQuery) Limit(n int) *FluxQuery {
+	if n <= 0 {
+		return b.fail("n", strconv.Itoa(n))
+	}
+
+	return b.add(func(fluxLiteral) string { return "limit(n: " + strconv.Itoa(n) + ")" })
+}
+
+func (b *FluxQuery) Keep(columns ...string) *FluxQuery {
+	return b.add(func(fluxLiteral) string { return "keep(columns: " + fluxStrings(columns) + ")" })
+}
+
+func (b *FluxQuery) Drop(columns ...string) *FluxQuery {
+	return b.add(func(fluxLiteral) string { return "drop(columns: " + fluxStrings(columns) + ")" })
+}
+
+func (b *FluxQuery) Yield(name string) *FluxQuery {
+	return b.add(func(fluxLiteral) string { return "yield(name: " + fluxString(name) + ")" })
+}
+
+func (b *FluxQuery) build(lit fluxLiteral) (query string, cols []string, err error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	suffixes := make([]string, len(b.stages))
+	for i, s := range b.stages {
+		suffixes[i] = s(lit)
+	}
+
+	return b.q.fluxQuery(b.bucket, b.start, b.stop, b.filter, suffixes, lit)
+}
+
+// Build returns the query and the columns of the filter structure like GenerateFluxQuery
+func (b *FluxQuery) Build() (query string, cols []string, err error) {
+	return b.build(quoteFluxLiteral)
+}
+
+// BuildWithParams returns the query with the values passed as params like GenerateFluxQueryWithParams
+func (b *FluxQuery) BuildWithParams() (query string, params map[string]any, cols []string, err error) {
+	p := &fluxParams{values: map[string]any{}, counts: map[string]int{}}
+
+	query, cols, err = b.build(p.literal)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	return query, p.values, cols, nil
+}
@@ -0,0 +1,82 @@
+package influxqu
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_FluxQuery(t *testing.T) {
+	type Data struct {
+		Base string  `influxqu:"measurement"`
+		Zone string  `influxqu:"tag,zone"`
+		Host string  `influxqu:"tag,host"`
+		F1   float64 `influxqu:"field,f1"`
+	}
+
+	g := NewinfluxQu()
+	filter := Data{Base: "cpu", Zone: "z1", Host: "h1", F1: 1}
+
+	q, cols, err := g.NewFluxQuery("bucket", filter).
+		Range(LastN(time.Hour)).
+		Filter("region", `eu"`).
+		AggregateWindow(90*time.Second, "mean", false).
+		Pivot().
+		Group("host").
+		Sort(true, "_time").
+		Limit(10).
+		Keep("_time", "host", "f1").
+		Drop("_start").
+		Yield("cpu").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `from(bucket: "bucket")
+ |> range(start: -1h)
+ |> filter(fn: (r) => r["_measurement"] == "cpu")
+ |> filter(fn: (r) => r["host"] == "h1")
+ |> filter(fn: (r) => r["zone"] == "z1")
+ |> filter(fn: (r) => r["_field"] == "f1")
+ |> filter(fn: (r) => r["region"] == "eu\"")
+ |> aggregateWindow(every: 1m30s, fn: mean, createEmpty: false)
+ |> ` + fluxPivot + `
+ |> group(columns: ["host"])
+ |> sort(columns: ["_time"], desc: true)
+ |> limit(n: 10)
+ |> keep(columns: ["_time", "host", "f1"])
+ |> drop(columns: ["_start"])
+ |> yield(name: "cpu")`
+
+	if q != expected {
+		t.Errorf("query is not expected, got:\n%s\nexpected:\n%s", q, expected)
+	}
+
+	if len(cols) != 4 || cols[0] != "_measurement" || cols[3] != "f1" {
+		t.Errorf("columns are not expected, got: %v", cols)
+	}
+
+	query, params, _, err := g.NewFluxQuery("bucket", filter).Filter("region", "eu").BuildWithParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params["filter0"] != "eu" || params["tag2"] != "z1" || query == "" {
+		t.Errorf("params are not expected, got: %v", params)
+	}
+
+	for _, b := range []*FluxQuery{
+		g.NewFluxQuery("bucket", filter).AggregateWindow(time.Minute, "mean()", true),
+		g.NewFluxQuery("bucket", filter).AggregateWindow(0, "mean", true),
+		g.NewFluxQuery("bucket", filter).Limit(0),
+	} {
+		if _, _, err := b.Build(); !errors.Is(err, ErrInvalidQueryArgument) {
+			t.Errorf("expected an invalid argument error, got: %v", err)
+		}
+	}
+
+	if _, _, err := g.NewFluxQuery("bucket", filter).Range(LastN(-time.Hour)).Build(); !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("expected an invalid time range error, got: %v", err)
+	}
+}
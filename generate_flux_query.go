@@ -1,14 +1,78 @@
 package influxqu
 
-import "reflect"
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	fluxEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `${`, `\${`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+	fluxDurationPattern = regexp.MustCompile(`^-?([0-9]+(y|mo|w|d|h|m|s|ms|us|µs|ns))+$`)
+	fluxIntPattern      = regexp.MustCompile(`^-?[0-9]+$`)
+)
+
+// fluxString quotes s as a Flux string literal, ${ is escaped so s is never interpolated
+func fluxString(s string) string {
+	return `"` + fluxEscaper.Replace(s) + `"`
+}
+
+// validFluxTime reports whether expr is a duration, a RFC3339 time, unix seconds, now() or a dashboard variable
+func validFluxTime(expr string) bool {
+	switch {
+	case expr == "now()", expr == "v.timeRangeStart", expr == "v.timeRangeStop":
+		return true
+	case fluxDurationPattern.MatchString(expr), fluxIntPattern.MatchString(expr):
+		return true
+	}
+
+	_, err := time.Parse(time.RFC3339Nano, expr)
+
+	return err == nil
+}
+
+// fluxLiteral renders a bucket, tag value or field name of the query, kind names the value
+type fluxLiteral func(kind, value string) string
+
+func quoteFluxLiteral(_, value string) string {
+	return fluxString(value)
+}
+
+// fluxParams renders the literals as params.<kind><n> references and collects their values
+type fluxParams struct {
+	values map[string]any
+	counts map[string]int
+}
+
+func (p *fluxParams) literal(kind, value string) string {
+	name := kind
+	if kind != "bucket" {
+		name += strconv.Itoa(p.counts[kind])
+		p.counts[kind]++
+	}
+
+	p.values[name] = value
+
+	return "params." + name
+}
 
 func (q *influxQu) generateFluxQuery(
 	bucket, start, end string,
 	tags map[string]string,
 	fields []string,
 	suffixes []string,
-) (query string, cols []string) {
-	query = "from(bucket: \"" + bucket + "\")"
+	lit fluxLiteral,
+) (query string, cols []string, err error) {
+	for _, t := range []string{start, end} {
+		if t != "" && !validFluxTime(t) {
+			return "", nil, &InvalidFluxTime{expr: t}
+		}
+	}
+
+	query = "from(bucket: " + lit("bucket", bucket) + ")"
 
 	if start != "" && end != "" {
 		query += "\n |> range(start: " + start + ", stop: " + end + ")"
@@ -18,8 +82,15 @@ func (q *influxQu) generateFluxQuery(
 		query += "\n |> range(stop: " + end + ")"
 	}
 
-	for k, v := range tags {
-		query = query + "\n |> filter(fn: (r) => r[\"" + k + "\"] == \"" + v + "\")"
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		query = query + "\n |> filter(fn: (r) => r[" + fluxString(k) + "] == " + lit("tag", tags[k]) + ")"
 		cols = append(cols, k)
 	}
 
@@ -30,7 +101,7 @@ func (q *influxQu) generateFluxQuery(
 			m += " or "
 		}
 
-		m += "r[\"_field\"] == \"" + f + "\""
+		m += "r[\"_field\"] == " + lit("field", f)
 		cols = append(cols, f)
 	}
 
@@ -42,20 +113,13 @@ func (q *influxQu) generateFluxQuery(
 		query += "\n |> " + s
 	}
 
-	return query, cols
+	return query, cols, nil
 }
 
-func (q *influxQu) GenerateFluxQuery(
-	bucket, start, end string, v interface{}, suffixes []string,
+func (q *influxQu) fluxQuery(
+	bucket, start, end string, v interface{}, suffixes []string, lit fluxLiteral,
 ) (query string, cols []string, err error) {
-	val := reflect.Indirect(reflect.ValueOf(v))
-	valType, valKind := getTypeInfo(v, val)
-
-	if valKind != reflect.Struct {
-		return "", nil, &UnSupportedType{}
-	}
-
-	measurement, tags, omitTags, f, _, err := q.getData(v, valType)
+	measurement, tags, omitTags, f, _, err := q.structData(v)
 	if err != nil {
 		return "", nil, err
 	}
@@ -72,8 +136,37 @@ func (q *influxQu) GenerateFluxQuery(
 		}
 	}
 
-	query, cols = q.generateFluxQuery(bucket, start, end, tags, fields, suffixes)
+	sort.Strings(fields)
+
+	query, cols, err = q.generateFluxQuery(bucket, start, end, tags, fields, suffixes, lit)
+	if err != nil {
+		return "", nil, err
+	}
+
 	cols = append(cols, omitTags...)
 
 	return query, cols, nil
 }
+
+// GenerateFluxQuery builds a Flux query filtering on the tags, measurement and fields of v,
+// the suffixes are appended as they are
+func (q *influxQu) GenerateFluxQuery(
+	bucket, start, end string, v interface{}, suffixes []string,
+) (query string, cols []string, err error) {
+	return q.fluxQuery(bucket, start, end, v, suffixes, quoteFluxLiteral)
+}
+
+// GenerateFluxQueryWithParams is GenerateFluxQuery with the bucket, tag values and field names passed as
+// params.bucket, params.tag<n> and params.field<n>, the params are sent with api.QueryAPI.QueryWithParams
+func (q *influxQu) GenerateFluxQueryWithParams(
+	bucket, start, end string, v interface{}, suffixes []string,
+) (query string, params map[string]any, cols []string, err error) {
+	p := &fluxParams{values: map[string]any{}, counts: map[string]int{}}
+
+	query, cols, err = q.fluxQuery(bucket, start, end, v, suffixes, p.literal)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	return query, p.values, cols, nil
+}
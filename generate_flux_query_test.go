@@ -1,6 +1,8 @@
 package influxqu
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -38,8 +40,8 @@ func Test_GenerateFluxQuery(t *testing.T) {
 
 	expected := `from(bucket: "bucket")
  |> range(start: -1h)
- |> filter(fn: (r) => r["t1"] == "abc")
  |> filter(fn: (r) => r["_measurement"] == "base")
+ |> filter(fn: (r) => r["t1"] == "abc")
  |> filter(fn: (r) => r["_field"] == "f1" or r["_field"] == "f2")
  |> sort("_time")
  |> last()`
@@ -53,7 +55,7 @@ func Test_GenerateFluxQuery(t *testing.T) {
 		t.Errorf("query is not expected, got: %s, expected: %s", q, expected)
 	}
 
-	expectedCols := []string{"t1", "_measurement", "f1", "f2", "t2", "t3", "t4", "t5", "t6"}
+	expectedCols := []string{"_measurement", "t1", "f1", "f2", "t2", "t3", "t4", "t5", "t6"}
 
 	if len(cols) != len(expectedCols) {
 		t.Errorf("columns are not expected, got: %v, expected: %v", cols, expectedCols)
@@ -65,3 +67,95 @@ func Test_GenerateFluxQuery(t *testing.T) {
 		}
 	}
 }
+
+func Benchmark_GenerateFluxQuery(b *testing.B) {
+	type Data struct {
+		Base string `influxqu:"measurement"`
+		T1   string `influxqu:"tag,t1"`
+		T2   string `influxqu:"tag,t2,omitempty"`
+		F1   int    `influxqu:"field,f1"`
+		F2   bool   `influxqu:"field,f2"`
+	}
+
+	g := NewinfluxQu()
+	data := Data{Base: "base", T1: "t1", F1: 1, F2: true}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, e := g.GenerateFluxQuery("bucket", "-1h", "", &data, nil); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
+
+func Test_GenerateFluxQuery_Escape(t *testing.T) {
+	type Data struct {
+		Base string `influxqu:"measurement"`
+		T1   string `influxqu:"tag,t1"`
+		F1   int    `influxqu:"field,f\"1"`
+	}
+
+	g := NewinfluxQu()
+	data := Data{Base: "base", T1: `a") |> drop() |> yield(name: "${x}\`, F1: 1}
+
+	q, _, err := g.GenerateFluxQuery(`my"bucket`, "2024-01-02T03:04:05Z", "now()", data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`from(bucket: "my\"bucket")`,
+		`range(start: 2024-01-02T03:04:05Z, stop: now())`,
+		`r["t1"] == "a\") |> drop() |> yield(name: \"\${x}\\")`,
+		`r["_field"] == "f\"1"`,
+	} {
+		if !strings.Contains(q, expected) {
+			t.Errorf("query does not contain %s, got: %s", expected, q)
+		}
+	}
+
+	for _, rng := range []string{"-1h) |> drop(", "1 hour", "-1x"} {
+		if _, _, err := g.GenerateFluxQuery("bucket", rng, "", data, nil); !errors.Is(err, ErrInvalidFluxTime) {
+			t.Errorf("expected an invalid time error for %q, got: %v", rng, err)
+		}
+	}
+
+	for _, rng := range []string{"-1h30m", "1700000000", "-2mo", "v.timeRangeStart"} {
+		if _, _, err := g.GenerateFluxQuery("bucket", rng, "", data, nil); err != nil {
+			t.Errorf("unexpected error for %q: %v", rng, err)
+		}
+	}
+}
+
+func Test_GenerateFluxQueryWithParams(t *testing.T) {
+	type Data struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+	}
+
+	g := NewinfluxQu()
+
+	q, params, cols, err := g.GenerateFluxQueryWithParams("bucket", "-1h", "", Data{Base: `x" or true`, F1: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `from(bucket: params.bucket)
+ |> range(start: -1h)
+ |> filter(fn: (r) => r["_measurement"] == params.tag0)
+ |> filter(fn: (r) => r["_field"] == params.field0)`
+
+	if q != expected {
+		t.Errorf("query is not expected, got: %s, expected: %s", q, expected)
+	}
+
+	if len(params) != 3 || params["bucket"] != "bucket" || params["tag0"] != `x" or true` || params["field0"] != "f1" {
+		t.Errorf("params are not expected, got: %v", params)
+	}
+
+	if len(cols) != 2 {
+		t.Errorf("columns are not expected, got: %v", cols)
+	}
+}
@@ -16,169 +16,156 @@ const (
 	decimalStructName = "Decimal"
 )
 
-func mergeOmitTags(org, src []string) ([]string, error) {
-	tmp := map[string]struct{}{}
-
-	for _, t := range org {
-		tmp[t] = struct{}{}
+func parseTag(tag string) []string {
+	tgs := strings.Split(tag, ",")
+	for i := range tgs {
+		tgs[i] = strings.TrimSpace(tgs[i])
 	}
 
-	for _, t := range src {
-		if _, ok := tmp[t]; ok {
-			return nil, &DuplicatedTag{}
-		}
-
-		tmp[t] = struct{}{}
+	return tgs
+}
 
-		org = append(org, t)
+func processTag(pf *planField, org map[string]string, f reflect.Value) (omiteTag string, err error) {
+	if pf.omitempty && f.IsZero() {
+		return pf.name, nil
 	}
 
-	return org, nil
-}
+	var v string
 
-func mergeTags(org, src map[string]string) error {
-	for k, v := range src {
-		if _, ok := org[k]; ok {
-			return &DuplicatedTag{}
+	if pf.kind == reflect.String && !pf.isPtr && !pf.marshaler {
+		v = f.String()
+	} else if pf.marshaler && !(pf.isPtr && f.IsNil()) {
+		if pf.isPtr {
+			f = f.Elem()
 		}
 
-		org[k] = v
-	}
-
-	return nil
-}
-
-func mergeFields(org, src map[string]interface{}) error {
-	for k, v := range src {
-		if _, ok := org[k]; ok {
-			return &DuplicatedField{}
+		if v, err = marshalTag(f); err != nil {
+			return "", err
 		}
+	} else if v, err = getFieldAsString(f); err != nil {
+		return "", err
+	}
 
-		org[k] = v
+	if !pf.omitempty || v != "" {
+		org[pf.name] = v
 	}
 
-	return nil
+	return "", nil
 }
 
-func processTag(tags []string, org map[string]string, val reflect.Value, i int) (omiteTag string, err error) {
-	if len(tags) < 2 {
-		return "", &NoTagName{}
+// scaleDecimal returns d multiplied by 10^scale, it fails instead of rounding or overflowing
+func scaleDecimal(field string, d decimal.Decimal, scale int32) (int64, error) {
+	s := d.Shift(scale)
+	if !s.IsInteger() || !s.BigInt().IsInt64() {
+		return 0, &InexactDecimal{field: field}
 	}
 
-	isOmitempty := false
-
-	if len(tags) == 3 {
-		if tags[2] != omitemptyKey {
-			return "", &UnSupportedTag{}
-		}
+	return s.IntPart(), nil
+}
 
-		isOmitempty = true
+// unsignedValue converts an integer field to uint64, so both clients write it with the u suffix
+func unsignedValue(field string, f reflect.Value) (uint64, error) {
+	if isUintKind(f.Kind()) {
+		return f.Uint(), nil
 	}
 
-	t := tags[1]
-
-	if _, ok := org[t]; ok {
-		return "", &DuplicatedTag{}
+	if f.Int() < 0 {
+		return 0, &NegativeUnsigned{field: field}
 	}
 
-	if val.IsValid() && val.Field(i).IsZero() {
-		if isOmitempty {
-			return t, nil
-		}
-	}
+	return uint64(f.Int()), nil
+}
 
-	v, err := getFieldAsString(val, i)
-	if err != nil {
-		return "", err
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// basicFieldValue converts a named bool, integer, float or string type to its basic type,
+// write.Point would write it as the string of fmt otherwise. time.Duration is kept as it is.
+func basicFieldValue(f reflect.Value) interface{} {
+	t := f.Type()
+	if t.PkgPath() == "" || t == durationType {
+		return f.Interface()
 	}
 
-	if !isOmitempty || v != "" {
-		org[t] = v
+	switch f.Kind() {
+	case reflect.Bool:
+		return f.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.Int()
+	case reflect.Float32, reflect.Float64:
+		return f.Float()
+	case reflect.String:
+		return f.String()
 	}
 
-	return "", nil
+	return f.Interface()
 }
 
-func processFields(tags []string, org map[string]interface{}, val reflect.Value, i int) (err error) {
-	if len(tags) < 2 {
-		return &NoFieldName{}
+func encodeDecimal(pf *planField, d decimal.Decimal) (interface{}, error) {
+	switch pf.decimalMode {
+	case decimalString:
+		return d.String(), nil
+	case decimalScaled:
+		return scaleDecimal(pf.name, d, pf.decimalScale)
 	}
 
-	isOmitempty := false
+	return d.InexactFloat64(), nil
+}
 
-	if len(tags) == 3 {
-		if tags[2] != omitemptyKey {
-			return &UnSupportedTag{}
+func processFields(pf *planField, org map[string]interface{}, f reflect.Value) error {
+	if pf.isPtr {
+		// a nil pointer has no value to write, the clients would write it as the string "<nil>"
+		if f.IsNil() {
+			return nil
 		}
 
-		isOmitempty = true
+		f = f.Elem()
 	}
 
-	f := tags[1]
+	if pf.marshaler {
+		if pf.omitempty && !pf.isPtr && f.IsZero() {
+			return nil
+		}
 
-	if _, ok := org[f]; ok {
-		return &DuplicatedField{}
+		return marshalField(pf, org, f)
 	}
 
-	v := val.Field(i).Interface()
-	if !isOmitempty || !isValueEmpty(v) {
-		// process decimal
-		if val.Field(i).Type().PkgPath() == decimalPkgPath && val.Field(i).Type().Name() == decimalStructName {
-			org[f] = v.(decimal.Decimal).InexactFloat64()
-		} else if val.Field(i).Kind() == reflect.Pointer {
-			underlyingVal := reflect.Indirect(val.Field(i))
-			org[f] = underlyingVal.Interface()
-		} else {
-			org[f] = v
+	if pf.isDecimal {
+		d := f.Interface().(decimal.Decimal)
+		if pf.omitempty && !pf.isPtr && d.IsZero() {
+			return nil
 		}
-	}
 
-	return nil
-}
+		v, err := encodeDecimal(pf, d)
+		if err != nil {
+			return err
+		}
 
-func (q *influxQu) processSubStruct(
-	val interface{},
-	ty reflect.Type,
-	measurement *string,
-	tags map[string]string,
-	omiteTags *[]string,
-	fields map[string]interface{},
-	timestamp *time.Time) (*time.Time, error) {
-	m, t, o, f, tp, e := q.getData(val, ty)
-	if e != nil {
-		return nil, e
-	}
+		org[pf.name] = v
 
-	if m != "" {
-		if *measurement != "" {
-			return nil, &DuplicatedMeasurement{}
-		}
+		return nil
+	}
 
-		*measurement = m
+	if pf.omitempty && !pf.isPtr && f.IsZero() {
+		return nil
 	}
 
-	if tp != nil {
-		if timestamp != nil {
-			return nil, &DuplicatedTimestamp{}
+	if pf.unsigned {
+		u, err := unsignedValue(pf.name, f)
+		if err != nil {
+			return err
 		}
-	}
 
-	if err := mergeTags(tags, t); err != nil {
-		return nil, err
-	}
+		org[pf.name] = u
 
-	if *omiteTags, e = mergeOmitTags(*omiteTags, o); e != nil {
-		return nil, e
+		return nil
 	}
 
-	if err := mergeFields(fields, f); err != nil {
-		return nil, err
-	}
+	org[pf.name] = basicFieldValue(f)
 
-	return tp, nil
+	return nil
 }
 
-func (q *influxQu) getData(v interface{}, t reflect.Type) (
+func (q *influxQu) getData(val reflect.Value, p *typePlan) (
 	measurement string,
 	tags map[string]string,
 	omiteTags []string,
@@ -186,47 +173,26 @@ func (q *influxQu) getData(v interface{}, t reflect.Type) (
 	timestamp *time.Time,
 	err error,
 ) {
-	val := reflect.Indirect(reflect.ValueOf(v))
 	tags = make(map[string]string)
-	fields = make(map[string]interface{})
+	fields = make(map[string]interface{}, len(p.fields))
 	omiteTags = make([]string, 0)
 
-	n := t.NumField()
-	for i := 0; i < n; i++ {
-		f := t.Field(i)
-		if f.Anonymous && (f.Type.Kind() == reflect.Struct || f.Type.Kind() == reflect.Ptr) {
-			timestamp, err = q.processSubStruct(val.Field(i).Interface(), f.Type, &measurement, tags, &omiteTags, fields, timestamp)
-			if err != nil {
-				return "", nil, nil, nil, nil, err
-			}
-		}
+	for i := range p.fields {
+		pf := &p.fields[i]
 
-		tag := f.Tag.Get(q.key)
-		if tag == "" {
+		f, ok := fieldByIndex(val, pf.index)
+		if !ok {
 			continue
 		}
 
-		tgs := strings.Split(tag, ",")
-		for i := range tgs {
-			tgs[i] = strings.TrimSpace(tgs[i])
-		}
-
-		switch tgs[0] {
-		case q.measurementKey:
-			if measurement != "" {
-				return "", nil, nil, nil, nil, &DuplicatedMeasurement{}
-			}
-
-			if len(tgs) != 1 {
-				return "", nil, nil, nil, nil, &UnSupportedTag{}
-			}
-
-			measurement, err = getFieldAsString(val, i)
+		switch pf.role {
+		case roleMeasurement:
+			measurement, err = getFieldAsString(f)
 			if err != nil {
 				return "", nil, nil, nil, nil, err
 			}
-		case q.tagKey:
-			omiteTag, er := processTag(tgs, tags, val, i)
+		case roleTag:
+			omiteTag, er := processTag(pf, tags, f)
 			if er != nil {
 				return "", nil, nil, nil, nil, er
 			}
@@ -234,19 +200,19 @@ func (q *influxQu) getData(v interface{}, t reflect.Type) (
 			if omiteTag != "" {
 				omiteTags = append(omiteTags, omiteTag)
 			}
-		case q.fieldKey:
-			err = processFields(tgs, fields, val, i)
-			if err != nil {
-				return "", nil, nil, nil, nil, err
+		case roleField:
+			if pf.expand {
+				err = p.expandFields(pf, fields, f)
+			} else {
+				err = processFields(pf, fields, f)
 			}
 
-		case q.timestampKey:
-			if timestamp != nil {
-				return "", nil, nil, nil, nil, &DuplicatedTimestamp{}
+			if err != nil {
+				return "", nil, nil, nil, nil, err
 			}
-
+		case roleTimestamp:
 			var tmp time.Time
-			tmp, err = getFieldAsTime(val, i)
+			tmp, err = getFieldAsTime(pf, f)
 
 			if err != nil {
 				return "", nil, nil, nil, nil, err
@@ -256,9 +222,79 @@ func (q *influxQu) getData(v interface{}, t reflect.Type) (
 		}
 	}
 
+	if measurement == "" {
+		measurement = p.staticMeasurement(val)
+	}
+
+	if err = p.getDynamicData(val, tags, fields); err != nil {
+		return "", nil, nil, nil, nil, err
+	}
+
 	return measurement, tags, omiteTags, fields, timestamp, nil
 }
 
+// structPlan resolves v to a struct value and the cached plan of its type
+func (q *influxQu) structPlan(v any) (reflect.Value, *typePlan, error) {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() != reflect.Struct {
+		return reflect.Value{}, nil, &UnSupportedType{}
+	}
+
+	p, err := q.typePlan(val.Type())
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+
+	return val, p, nil
+}
+
+// structData resolves v to a struct value and reads it through the cached plan of its type
+func (q *influxQu) structData(v any) (
+	measurement string,
+	tags map[string]string,
+	omiteTags []string,
+	fields map[string]interface{},
+	timestamp *time.Time,
+	err error,
+) {
+	val, p, err := q.structPlan(v)
+	if err != nil {
+		return "", nil, nil, nil, nil, err
+	}
+
+	return q.planData(val, p)
+}
+
+// planData reads val through p and applies the default tags and measurement prefix
+func (q *influxQu) planData(val reflect.Value, p *typePlan) (
+	measurement string,
+	tags map[string]string,
+	omiteTags []string,
+	fields map[string]interface{},
+	timestamp *time.Time,
+	err error,
+) {
+	measurement, tags, omiteTags, fields, timestamp, err = q.getData(val, p)
+	if err != nil {
+		return "", nil, nil, nil, nil, err
+	}
+
+	if measurement, err = q.applyDefaults(measurement, tags, p.tagNames); err != nil {
+		return "", nil, nil, nil, nil, err
+	}
+
+	return measurement, tags, omiteTags, fields, timestamp, nil
+}
+
+// dropNilFields removes the fields without a value, like appendLine leaves them out of the line
+func dropNilFields(fields map[string]any) {
+	for k, v := range fields {
+		if v == nil {
+			delete(fields, k)
+		}
+	}
+}
+
 func (q *influxQu) generateCommonPointInfo(v any) (
 	measurement string,
 	tags map[string]string,
@@ -266,14 +302,19 @@ func (q *influxQu) generateCommonPointInfo(v any) (
 	timestamp time.Time,
 	err error,
 ) {
-	val := reflect.Indirect(reflect.ValueOf(v))
-	valType, valKind := getTypeInfo(v, val)
+	if pm, ok := v.(InfluxPointMarshaler); ok {
+		if measurement, tags, fields, timestamp, err = marshalPoint(pm); err != nil {
+			return "", nil, nil, time.Time{}, err
+		}
 
-	if valKind != reflect.Struct {
-		return "", nil, nil, time.Time{}, &UnSupportedType{}
+		if measurement, err = q.applyDefaults(measurement, tags, nil); err != nil {
+			return "", nil, nil, time.Time{}, err
+		}
+
+		return measurement, tags, fields, timestamp, nil
 	}
 
-	m, t, _, f, tp, err := q.getData(v, valType)
+	m, t, _, f, tp, err := q.structData(v)
 	if err != nil {
 		return "", nil, nil, time.Time{}, err
 	}
@@ -282,6 +323,8 @@ func (q *influxQu) generateCommonPointInfo(v any) (
 		return "", nil, nil, time.Time{}, &NoValidMeasurement{}
 	}
 
+	dropNilFields(f)
+
 	if len(f) == 0 {
 		return "", nil, nil, time.Time{}, &NoValidField{}
 	}
@@ -295,6 +338,10 @@ func (q *influxQu) generateCommonPointInfo(v any) (
 }
 
 func (q *influxQu) GenerateInfluxPoint(v any) (*write.Point, error) {
+	if g, ok := v.(InfluxPointGenerator); ok && !q.hasDefaults() && q.generatedFor(v) {
+		return g.ToInfluxPoint()
+	}
+
 	m, t, f, tp, err := q.generateCommonPointInfo(v)
 	if err != nil {
 		return nil, err
@@ -309,5 +356,15 @@ func (q *influxQu) GenerateInfluxPointV3(v any) (*influxdb3.Point, error) {
 		return nil, err
 	}
 
-	return influxdb3.NewPoint(m, t, f, tp), nil
+	p := influxdb3.NewPoint(m, t, nil, tp)
+
+	for k, v := range f {
+		if u, ok := v.(uint64); ok {
+			p.SetUIntegerField(k, u)
+		} else {
+			p.SetField(k, v)
+		}
+	}
+
+	return p, nil
 }
@@ -3,6 +3,7 @@ package influxqu
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -204,6 +205,67 @@ func Test_GenerateInfluxPoint_Nested_Struct(t *testing.T) {
 	}
 }
 
+func Test_GenerateInfluxPoint_Inline_Struct(t *testing.T) {
+	type Reading struct {
+		Value float64 `influxqu:"field,value"`
+		Unit  string  `influxqu:"tag,unit"`
+	}
+
+	type Sensor struct {
+		Temperature Reading  `influxqu:"inline,prefix=temp_"`
+		Humidity    *Reading `influxqu:"inline,prefix=hum_"`
+	}
+
+	type Data struct {
+		Base   string `influxqu:"measurement"`
+		Room   Sensor `influxqu:"inline,prefix=room_"`
+		Spare  *Sensor
+		Ignore Sensor
+	}
+
+	g := NewinfluxQu()
+	data := Data{
+		Base: "base",
+		Room: Sensor{
+			Temperature: Reading{Value: 21.5, Unit: "C"},
+		},
+	}
+
+	p, e := g.GenerateInfluxPoint(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if e := checkTags(p, map[string]string{"room_temp_unit": "C"}); e != nil || len(p.TagList()) != 1 {
+		t.Errorf("tags are not expected: %v %v", p.TagList(), e)
+	}
+
+	if e := checkFields(p, map[string]interface{}{"room_temp_value": 21.5}); e != nil || len(p.FieldList()) != 1 {
+		t.Errorf("fields are not expected: %v %v", p.FieldList(), e)
+	}
+
+	data.Room.Humidity = &Reading{Value: 40}
+
+	b, e := g.MarshalLineProtocol(&data, time.Second)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if !strings.HasPrefix(string(b), "base,room_temp_unit=C room_hum_value=40,room_temp_value=21.5 ") {
+		t.Errorf("line protocol is not expected, got: %q", b)
+	}
+
+	type Collision struct {
+		Base  string  `influxqu:"measurement"`
+		Value float64 `influxqu:"field,temp_value"`
+		Inner Sensor  `influxqu:"inline"`
+	}
+
+	if _, e := g.GenerateInfluxPoint(&Collision{Base: "base"}); e == nil || e.Error() != "duplicated field temp_value" {
+		t.Errorf("expected a duplicated field error, got: %v", e)
+	}
+}
+
 func Test_GenerateInfluxPoint_Omitempty(t *testing.T) {
 	type Data struct {
 		Base      string    `influxqu:"measurement"`
@@ -379,6 +441,105 @@ func Test_GenerateInfluxPoint_Using_Decimal(t *testing.T) {
 	}
 }
 
+func Test_GenerateInfluxPoint_Decimal_Modes(t *testing.T) {
+	type Data struct {
+		Base   string           `influxqu:"measurement"`
+		Amount decimal.Decimal  `influxqu:"field,amount,decimal=scaled:4"`
+		Total  *decimal.Decimal `influxqu:"field,total,decimal=string"`
+		Rate   decimal.Decimal  `influxqu:"field,rate,decimal=float"`
+	}
+
+	g := NewinfluxQu()
+	total := decimal.RequireFromString("12345678901234567890.123456789")
+	data := Data{
+		Base:   "ledger",
+		Amount: decimal.RequireFromString("-10.0125"),
+		Total:  &total,
+		Rate:   decimal.NewFromFloat(0.5),
+	}
+
+	p, e := g.GenerateInfluxPoint(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if e := checkFields(p, map[string]interface{}{"amount": int64(-100125), "total": total.String(), "rate": 0.5}); e != nil {
+		t.Error(e)
+	}
+
+	data.Amount = decimal.RequireFromString("0.00001")
+	if _, e := g.GenerateInfluxPoint(&data); e == nil || e.Error() != "decimal value of field amount can not be scaled to an integer exactly" {
+		t.Errorf("expected an inexact decimal error, got: %v", e)
+	}
+
+	data.Amount = decimal.RequireFromString("1e16")
+	if _, e := g.GenerateInfluxPoint(&data); e == nil {
+		t.Error("expected an error for an overflowing decimal")
+	}
+}
+
+func Test_GenerateInfluxPoint_Timestamp_Options(t *testing.T) {
+	type Seconds struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+		At   int64  `influxqu:"timestamp,unix=s"`
+	}
+
+	type Millis struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+		At   *int64 `influxqu:"timestamp,unix=ms"`
+	}
+
+	type Micros struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+		At   uint64 `influxqu:"timestamp,unix=us"`
+	}
+
+	type Nanos struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+		At   int64  `influxqu:"timestamp,unix=ns"`
+	}
+
+	type Layout struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+		At   string `influxqu:"timestamp,layout=2006-01-02 15:04:05"`
+	}
+
+	g := NewinfluxQu()
+	ms := int64(1700000000123)
+	expected := time.Unix(1700000000, 123000000)
+
+	cases := []struct {
+		data any
+		ts   time.Time
+	}{
+		{&Seconds{Base: "b", F1: 1, At: 1700000000}, time.Unix(1700000000, 0)},
+		{&Millis{Base: "b", F1: 1, At: &ms}, expected},
+		{&Micros{Base: "b", F1: 1, At: 1700000000123000}, expected},
+		{&Nanos{Base: "b", F1: 1, At: 1700000000123000000}, expected},
+		{&Layout{Base: "b", F1: 1, At: "2023-11-14 22:13:20"}, time.Unix(1700000000, 0)},
+	}
+
+	for _, c := range cases {
+		p, e := g.GenerateInfluxPoint(c.data)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		if !p.Time().Equal(c.ts) {
+			t.Errorf("timestamp of %T is not expected, got: %v, expected: %v", c.data, p.Time(), c.ts)
+		}
+	}
+
+	if _, e := g.GenerateInfluxPoint(&Layout{Base: "b", F1: 1, At: "now"}); e == nil {
+		t.Error("expected an error for a timestamp not matching the layout")
+	}
+}
+
 func Test_GenerateInfluxPoint_Using_Pointer(t *testing.T) {
 	type Data struct {
 		Base      string    `influxqu:"measurement"`
@@ -483,8 +644,95 @@ func Test_GenerateInfluxPoint_Using_Nil_Pointer(t *testing.T) {
 	}
 
 	if e := checkFields(p, map[string]interface{}{
-		field1: int64(data.F1), field2: data.F2, field3: nil,
+		field1: int64(data.F1), field2: data.F2,
 	}); e != nil {
 		t.Error(e)
 	}
 }
+
+func Test_GenerateInfluxPoint_Nil_Pointer_Without_Omitempty(t *testing.T) {
+	type Data struct {
+		Base  string  `influxqu:"measurement"`
+		Count *int    `influxqu:"field,count"`
+		Usage float64 `influxqu:"field,usage"`
+	}
+
+	g := NewinfluxQu()
+	data := Data{Base: "base", Usage: 0.5}
+
+	p, e := g.GenerateInfluxPoint(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if len(p.FieldList()) != 1 || p.FieldList()[0].Key != "usage" {
+		t.Errorf("nil field is written, got: %v", p.FieldList())
+	}
+
+	p3, e := g.GenerateInfluxPointV3(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if names := p3.GetFieldNames(); len(names) != 1 || names[0] != "usage" {
+		t.Errorf("nil field is written, got: %v", names)
+	}
+
+	type Counter struct {
+		Base  string `influxqu:"measurement"`
+		Count *int   `influxqu:"field,count"`
+	}
+
+	if _, e := g.GenerateInfluxPoint(&Counter{Base: "base"}); e == nil || e.Error() != "no valid field" {
+		t.Errorf("expected a no valid field error, got: %v", e)
+	}
+
+	if _, e := g.GenerateInfluxPointV3(&Counter{Base: "base"}); e == nil || e.Error() != "no valid field" {
+		t.Errorf("expected a no valid field error, got: %v", e)
+	}
+}
+
+func Benchmark_GenerateInfluxPoint(b *testing.B) {
+	type Tag struct {
+		T3 string `influxqu:"tag,t3,omitempty"`
+	}
+
+	type Data struct {
+		Tag
+		Base      string          `influxqu:"measurement"`
+		T1        string          `influxqu:"tag,t1"`
+		T2        *string         `influxqu:"tag,t2"`
+		F1        int             `influxqu:"field,f1"`
+		F2        bool            `influxqu:"field,f2"`
+		F3        *float64        `influxqu:"field,f3,omitempty"`
+		F4        decimal.Decimal `influxqu:"field,f4"`
+		F5        string          `influxqu:"field,f5"`
+		Timestamp time.Time       `influxqu:"timestamp"`
+	}
+
+	t2 := "t2"
+	f3 := 1.5
+
+	g := NewinfluxQu()
+	data := Data{
+		Tag:       Tag{T3: "t3"},
+		Base:      "base",
+		T1:        "t1",
+		T2:        &t2,
+		F1:        1,
+		F2:        true,
+		F3:        &f3,
+		F4:        decimal.NewFromFloat(1.35),
+		F5:        "f5",
+		Timestamp: time.Now(),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, e := g.GenerateInfluxPoint(&data); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package influxqu
+
+import (
+	"reflect"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+func sliceElements(v any) ([]any, error) {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() != reflect.Slice {
+		return nil, &UnSupportedType{}
+	}
+
+	if _, elemKind := getTypeInfo(v, val); elemKind != reflect.Struct {
+		return nil, &UnSupportedType{}
+	}
+
+	elems := make([]any, val.Len())
+
+	for i := range elems {
+		elem := val.Index(i)
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			return nil, &ElementError{index: i, err: &UnSupportedType{}}
+		}
+
+		if elem.Kind() == reflect.Struct {
+			elem = elem.Addr()
+		}
+
+		elems[i] = elem.Interface()
+	}
+
+	return elems, nil
+}
+
+func (q *influxQu) GenerateInfluxPoints(v any) ([]*write.Point, error) {
+	elems, err := sliceElements(v)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]*write.Point, len(elems))
+
+	for i, elem := range elems {
+		if points[i], err = q.GenerateInfluxPoint(elem); err != nil {
+			return nil, &ElementError{index: i, err: err}
+		}
+	}
+
+	return points, nil
+}
+
+func (q *influxQu) GenerateInfluxPointsV3(v any) ([]*influxdb3.Point, error) {
+	elems, err := sliceElements(v)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]*influxdb3.Point, len(elems))
+
+	for i, elem := range elems {
+		if points[i], err = q.GenerateInfluxPointV3(elem); err != nil {
+			return nil, &ElementError{index: i, err: err}
+		}
+	}
+
+	return points, nil
+}
@@ -0,0 +1,83 @@
+package influxqu
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_GenerateInfluxPoints(t *testing.T) {
+	type Data struct {
+		Base      string    `influxqu:"measurement"`
+		T1        string    `influxqu:"tag,t1"`
+		F1        int       `influxqu:"field,f1"`
+		Timestamp time.Time `influxqu:"timestamp"`
+	}
+
+	now := time.Now()
+	data := []Data{
+		{Base: "base", T1: "a", F1: 1, Timestamp: now},
+		{Base: "base", T1: "b", F1: 2, Timestamp: now},
+	}
+
+	g := NewinfluxQu()
+
+	for _, v := range []any{data, &data, []*Data{&data[0], &data[1]}} {
+		points, e := g.GenerateInfluxPoints(v)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		if len(points) != len(data) {
+			t.Fatalf("points are not expected, got: %d, expected: %d", len(points), len(data))
+		}
+
+		for i, p := range points {
+			if e := checkTags(p, map[string]string{"t1": data[i].T1}); e != nil {
+				t.Error(e)
+			}
+
+			if e := checkFields(p, map[string]interface{}{"f1": int64(data[i].F1)}); e != nil {
+				t.Error(e)
+			}
+		}
+	}
+
+	points, e := g.GenerateInfluxPointsV3(data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if len(points) != len(data) || points[1].GetMeasurement() != "base" {
+		t.Errorf("points are not expected, got: %v", points)
+	}
+}
+
+func Test_GenerateInfluxPoints_Element_Error(t *testing.T) {
+	type Data struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+	}
+
+	g := NewinfluxQu()
+
+	_, e := g.GenerateInfluxPoints([]Data{{Base: "base", F1: 1}, {F1: 2}})
+
+	var elemErr *ElementError
+	if !errors.As(e, &elemErr) || elemErr.Index() != 1 {
+		t.Fatalf("expected an element error at index 1, got: %v", e)
+	}
+
+	var noMeasurement *NoValidMeasurement
+	if !errors.As(e, &noMeasurement) {
+		t.Errorf("expected a wrapped no valid measurement error, got: %v", e)
+	}
+
+	if _, e := g.GenerateInfluxPointsV3([]*Data{{Base: "base", F1: 1}, nil}); e == nil {
+		t.Error("expected an error for nil element")
+	}
+
+	if _, e := g.GenerateInfluxPoint([]Data{{Base: "base", F1: 1}}); e == nil {
+		t.Error("expected an error for slice in GenerateInfluxPoint")
+	}
+}
@@ -4,12 +4,12 @@ go 1.23.10
 
 require (
 	github.com/InfluxCommunity/influxdb3-go/v2 v2.10.0
+	github.com/apache/arrow-go/v18 v18.4.1
 	github.com/influxdata/influxdb-client-go/v2 v2.9.2
 	github.com/shopspring/decimal v1.3.1
 )
 
 require (
-	github.com/apache/arrow-go/v18 v18.4.1 // indirect
 	github.com/deepmap/oapi-codegen v1.11.0 // indirect
 	github.com/goccy/go-json v0.10.5 // indirect
 	github.com/google/flatbuffers v25.2.10+incompatible // indirect
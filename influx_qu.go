@@ -1,18 +1,70 @@
 package influxqu
 
 import (
+	"io"
+	"sync"
+	"time"
+
 	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
 	"github.com/influxdata/influxdb-client-go/v2/api/write"
 )
 
 type InfluxQu interface {
 	GenerateInfluxPoint(val any) (*write.Point, error)
 	GenerateInfluxPointV3(val any) (*influxdb3.Point, error)
+	GenerateInfluxPoints(val any) ([]*write.Point, error)
+	GenerateInfluxPointsV3(val any) ([]*influxdb3.Point, error)
+	MarshalLineProtocol(val any, precision time.Duration) ([]byte, error)
+	AppendLineProtocol(dst []byte, val any) ([]byte, error)
 	GenerateFluxQuery(bucket, start, end string, val any, suffix []string) (query string, cols []string, err error)
+	NewFluxQuery(bucket string, filter any) *FluxQuery
+	GenerateSQLQuery(table string, rng TimeRange, val any, opts ...SQLOption) (query string, params influxdb3.QueryParameters, err error)
+	GenerateInfluxQLQuery(measurement string, rng TimeRange, val any, opts ...InfluxQLOption) (query string, err error)
+	GenerateFluxQueryWithParams(bucket, start, end string, val any, suffix []string) (query string, params map[string]any, cols []string, err error)
+	DecodeFluxRecord(rec *query.FluxRecord, dst any) error
+	DecodeFluxTable(res *api.QueryTableResult, dst any) error
+	DecodeSQLRow(row map[string]any, dst any) error
+	DecodeSQLRows(it *influxdb3.QueryIterator, dst any) error
+	DecodeInfluxQLResponse(r io.Reader, epoch time.Duration, dst any) error
+	DecodeArrowRecord(rec arrow.RecordBatch, dst any) error
+	DecodeArrowReader(reader array.RecordReader, dst any) error
+	Validate(v any) error
+}
+
+// InfluxMeasurer is implemented by types always written to the same measurement,
+// it is used when the type has no measurement field or the field is empty
+type InfluxMeasurer interface {
+	InfluxMeasurement() string
 }
 
 const (
 	omitemptyKey = "omitempty"
+	decimalKey   = "decimal"
+	typeKey      = "type"
+	unixKey      = "unix"
+	layoutKey    = "layout"
+	inlineKey    = "inline"
+	tagsKey      = "tags"
+	expandKey    = "expand"
+	fieldsKey    = "fields"
+	prefixKey    = "prefix"
+	nameKey      = "name"
+
+	typeUintValue = "uint"
+
+	decimalFloatValue  = "float"
+	decimalStringValue = "string"
+	decimalScaledValue = "scaled"
+
+	// the field name and the element index in an expanded field name
+	defaultExpandFormat = "%s_%d"
+
+	// an int64 holds 18 decimal digits
+	maxDecimalScale = 18
 )
 
 type influxQu struct {
@@ -21,14 +73,22 @@ type influxQu struct {
 	fieldKey       string
 	tagKey         string
 	timestampKey   string
+
+	defaultTags       map[string]string
+	measurementPrefix string
+	strictDefaults    bool
+	naming            NamingStrategy
+
+	plans      sync.Map
+	arrowPlans sync.Map
 }
 
-func NewinfluxQu() InfluxQu {
-	i, _ := NewinfluxQuWithKeys("influxqu", "measurement", "tag", "field", "timestamp")
+func NewinfluxQu(opts ...Option) InfluxQu {
+	i, _ := NewinfluxQuWithKeys("influxqu", "measurement", "tag", "field", "timestamp", opts...)
 	return i
 }
 
-func NewinfluxQuWithKeys(key string, measurementKey string, tagKey string, fieldKey string, timestampKey string) (InfluxQu, error) {
+func NewinfluxQuWithKeys(key string, measurementKey string, tagKey string, fieldKey string, timestampKey string, opts ...Option) (InfluxQu, error) {
 	if key == "" {
 		key = "influxqu"
 	}
@@ -61,11 +121,17 @@ func NewinfluxQuWithKeys(key string, measurementKey string, tagKey string, field
 		return nil, &DuplicatedKey{}
 	}
 
-	return &influxQu{
+	q := &influxQu{
 		key:            key,
 		measurementKey: measurementKey,
 		fieldKey:       fieldKey,
 		tagKey:         tagKey,
 		timestampKey:   timestampKey,
-	}, nil
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q, nil
 }
@@ -0,0 +1,162 @@
+package influxqu
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var influxQLFillPattern = regexp.MustCompile(`^(null|none|previous|linear|-?[0-9]+(\.[0-9]+)?)$`)
+
+type influxQLOptions struct {
+	interval  time.Duration
+	aggregate string
+	fill      string
+	limit     int
+}
+
+// InfluxQLOption configures GenerateInfluxQLQuery
+type InfluxQLOption func(o *influxQLOptions)
+
+// WithGroupByTime groups the points into interval wide windows and aggregates every field with the
+// InfluxQL function aggregate, like mean or max. The tags of the filter structure are grouped by too.
+func WithGroupByTime(interval time.Duration, aggregate string) InfluxQLOption {
+	return func(o *influxQLOptions) {
+		o.interval = interval
+		o.aggregate = aggregate
+	}
+}
+
+// WithFill sets the fill of empty windows, null, none, previous, linear or a number
+func WithFill(fill string) InfluxQLOption {
+	return func(o *influxQLOptions) {
+		o.fill = fill
+	}
+}
+
+func WithLimit(n int) InfluxQLOption {
+	return func(o *influxQLOptions) {
+		o.limit = n
+	}
+}
+
+var influxQLEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// influxQLIdentifier quotes s as an InfluxQL identifier
+func influxQLIdentifier(s string) string {
+	return `"` + influxQLEscaper.Replace(s) + `"`
+}
+
+var influxQLStringEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`)
+
+// influxQLString quotes s as an InfluxQL string literal
+func influxQLString(s string) string {
+	return "'" + influxQLStringEscaper.Replace(s) + "'"
+}
+
+func (o *influxQLOptions) validate() error {
+	switch {
+	case (o.aggregate != "" || o.interval != 0) && (o.interval <= 0 || !identifierPattern.MatchString(o.aggregate)):
+		return &InvalidQueryArgument{name: "aggregate", value: o.aggregate}
+	case o.fill != "" && (o.aggregate == "" || !influxQLFillPattern.MatchString(o.fill)):
+		return &InvalidQueryArgument{name: "fill", value: o.fill}
+	case o.limit < 0:
+		return &InvalidQueryArgument{name: "limit", value: strconv.Itoa(o.limit)}
+	}
+
+	return nil
+}
+
+// GenerateInfluxQLQuery builds an InfluxQL query selecting the fields and tags of v from measurement,
+// the measurement of v when it is empty. The tag values of v are compared in the WHERE clause.
+func (q *influxQu) GenerateInfluxQLQuery(measurement string, rng TimeRange, v any, opts ...InfluxQLOption) (string, error) {
+	var o influxQLOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := o.validate(); err != nil {
+		return "", err
+	}
+
+	if err := rng.Validate(); err != nil {
+		return "", err
+	}
+
+	val, p, err := q.structPlan(v)
+	if err != nil {
+		return "", err
+	}
+
+	m, tags, _, values, _, err := q.planData(val, p)
+	if err != nil {
+		return "", err
+	}
+
+	if measurement == "" {
+		measurement = m
+	}
+
+	if measurement == "" {
+		return "", &NoValidMeasurement{}
+	}
+
+	tagColumns, fieldColumns := sqlColumns(p, values)
+
+	columns := make([]string, 0, len(fieldColumns)+len(tagColumns))
+	for _, f := range fieldColumns {
+		if o.aggregate != "" {
+			columns = append(columns, o.aggregate+"("+influxQLIdentifier(f)+") AS "+influxQLIdentifier(f))
+		} else {
+			columns = append(columns, influxQLIdentifier(f))
+		}
+	}
+
+	// the tags of an aggregated query come back as the tags of its series
+	if o.aggregate == "" {
+		for _, t := range tagColumns {
+			columns = append(columns, influxQLIdentifier(t))
+		}
+	}
+
+	if o.aggregate == "" && (p.tagMap != nil || p.fieldMap != nil || p.hasExpand) {
+		columns = []string{"*"}
+	}
+
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM " + influxQLIdentifier(measurement)
+
+	conds := make([]string, 0, len(tags)+1)
+	for _, k := range sortedKeys(tags) {
+		conds = append(conds, influxQLIdentifier(k)+" = "+influxQLString(tags[k]))
+	}
+
+	if c := rng.InfluxQL(); c != "" {
+		conds = append(conds, c)
+	}
+
+	if len(conds) != 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	if o.aggregate != "" {
+		n, unit := exactUnit(o.interval, influxQLUnits)
+		group := []string{"time(" + strconv.FormatInt(n, 10) + unit + ")"}
+
+		for _, t := range tagColumns {
+			group = append(group, influxQLIdentifier(t))
+		}
+
+		query += " GROUP BY " + strings.Join(group, ", ")
+	}
+
+	if o.fill != "" {
+		query += " fill(" + o.fill + ")"
+	}
+
+	if o.limit > 0 {
+		query += " LIMIT " + strconv.Itoa(o.limit)
+	}
+
+	return query, nil
+}
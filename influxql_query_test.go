@@ -0,0 +1,162 @@
+package influxqu
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_GenerateInfluxQLQuery(t *testing.T) {
+	type Data struct {
+		Base  string  `influxqu:"measurement"`
+		Host  string  `influxqu:"tag,host"`
+		Zone  string  `influxqu:"tag,zone,omitempty"`
+		Usage float64 `influxqu:"field,usage"`
+		Load  int     `influxqu:"field,load"`
+	}
+
+	g := NewinfluxQu()
+	filter := Data{Base: `cpu"x`, Host: `h1' OR 1=1 \`}
+
+	q, err := g.GenerateInfluxQLQuery("", LastN(90*time.Second), &filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `SELECT "usage", "load", "host", "zone" FROM "cpu\"x" WHERE "host" = 'h1\' OR 1=1 \\' AND time >= now() - 90s`
+	if q != expected {
+		t.Errorf("query is not expected, got: %s, expected: %s", q, expected)
+	}
+
+	filter.Load = 1
+
+	q, err = g.GenerateInfluxQLQuery("cpu", TimeRange{}, &filter, WithGroupByTime(time.Minute, "mean"), WithFill("previous"), WithLimit(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `SELECT mean("load") AS "load" FROM "cpu" WHERE "host" = 'h1\' OR 1=1 \\' GROUP BY time(1m), "host", "zone" fill(previous) LIMIT 10`
+	if q != expected {
+		t.Errorf("query is not expected, got: %s, expected: %s", q, expected)
+	}
+
+	for _, opt := range []InfluxQLOption{
+		WithGroupByTime(time.Minute, "mean(1); DROP"),
+		WithGroupByTime(0, "mean"),
+		WithGroupByTime(time.Minute, ""),
+		WithFill("0) DROP"),
+		WithFill("null"),
+		WithLimit(-1),
+	} {
+		if _, err := g.GenerateInfluxQLQuery("cpu", TimeRange{}, &filter, opt); !errors.Is(err, ErrInvalidQueryArgument) {
+			t.Errorf("expected an invalid argument error, got: %v", err)
+		}
+	}
+
+	if _, err := g.GenerateInfluxQLQuery("cpu", LastN(-time.Hour), &filter); !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("expected an invalid time range error, got: %v", err)
+	}
+
+	type Dynamic struct {
+		Host   string             `influxqu:"tag,host"`
+		Values map[string]float64 `influxqu:"fields"`
+	}
+
+	if _, err := g.GenerateInfluxQLQuery("", TimeRange{}, Dynamic{Host: "h"}); !errors.Is(err, ErrNoValidMeasurement) {
+		t.Errorf("expected a no measurement error, got: %v", err)
+	}
+
+	q, err = g.GenerateInfluxQLQuery("metrics", TimeRange{}, Dynamic{Host: "h"})
+	if err != nil || q != `SELECT * FROM "metrics" WHERE "host" = 'h'` {
+		t.Errorf("query is not expected, got: %s, %v", q, err)
+	}
+}
+
+func Test_DecodeInfluxQLResponse(t *testing.T) {
+	type Data struct {
+		Base  string             `influxqu:"measurement"`
+		Host  string             `influxqu:"tag,host"`
+		Usage float64            `influxqu:"field,usage"`
+		Load  uint64             `influxqu:"field,load"`
+		Extra map[string]float64 `influxqu:"fields"`
+		At    time.Time          `influxqu:"timestamp"`
+	}
+
+	body := `{"results":[{"statement_id":0,"series":[
+		{"name":"cpu","tags":{"host":"h1"},"columns":["time","usage","load","temp"],"values":[
+			["2024-01-02T03:04:05Z",0.5,18446744073709551615,36.6],
+			["2024-01-02T03:05:05Z",1,null,null]
+		]},
+		{"name":"cpu","tags":{"host":"h2"},"columns":["time","usage"],"values":[["2023-11-14T22:13:20Z",2.5]]}
+	]}]}`
+
+	g := NewinfluxQu()
+
+	var rows []*Data
+	if err := g.DecodeInfluxQLResponse(strings.NewReader(body), 0, &rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("rows are not expected, got: %d", len(rows))
+	}
+
+	first := rows[0]
+	if first.Base != "cpu" || first.Host != "h1" || first.Usage != 0.5 || first.Load != 1<<64-1 || first.Extra["temp"] != 36.6 ||
+		!first.At.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("row is not expected, got: %+v", first)
+	}
+
+	if rows[1].Usage != 1 || rows[1].Load != 0 {
+		t.Errorf("row is not expected, got: %+v", rows[1])
+	}
+
+	if rows[2].Host != "h2" || rows[2].Usage != 2.5 || !rows[2].At.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("row is not expected, got: %+v", rows[2])
+	}
+
+	epochs := map[time.Duration]string{
+		time.Nanosecond:  "1700000000000000000",
+		time.Millisecond: "1700000000000",
+		time.Second:      "1700000000",
+		time.Hour:        "472222",
+	}
+
+	for epoch, n := range epochs {
+		var data []Data
+
+		body := `{"results":[{"series":[{"name":"cpu","columns":["time","usage"],"values":[[` + n + `,2.5]]}]}]}`
+		if err := g.DecodeInfluxQLResponse(strings.NewReader(body), epoch, &data); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := time.Unix(1700000000, 0).Truncate(epoch); len(data) != 1 || !data[0].At.Equal(expected) {
+			t.Errorf("time of epoch %s is not expected, got: %+v, expected: %v", epoch, data, expected)
+		}
+	}
+
+	var data []Data
+
+	numeric := `{"results":[{"series":[{"name":"cpu","columns":["time","usage"],"values":[[1700000000,2.5]]}]}]}`
+	if err := g.DecodeInfluxQLResponse(strings.NewReader(numeric), 0, &data); !errors.Is(err, ErrMismatchedType) {
+		t.Errorf("expected a mismatched type error for a numeric time without epoch, got: %v", err)
+	}
+
+	if err := g.DecodeInfluxQLResponse(strings.NewReader(numeric), 2*time.Second, &data); !errors.Is(err, ErrUnSupportedPrecision) {
+		t.Errorf("expected an unsupported precision error, got: %v", err)
+	}
+
+	err := g.DecodeInfluxQLResponse(strings.NewReader(`{"results":[{"statement_id":0,"error":"database not found: db"}]}`), 0, &data)
+	if !errors.Is(err, ErrInfluxQL) || err.Error() != "influxql query failed: database not found: db" {
+		t.Errorf("expected an influxql error, got: %v", err)
+	}
+
+	if err := g.DecodeInfluxQLResponse(strings.NewReader(`{"error":"unauthorized"}`), 0, &data); !errors.Is(err, ErrInfluxQL) {
+		t.Errorf("expected an influxql error, got: %v", err)
+	}
+
+	if err := g.DecodeInfluxQLResponse(strings.NewReader(`{"results":[]}`), 0, data); !errors.Is(err, ErrUnSupportedType) {
+		t.Errorf("expected an unsupported type error, got: %v", err)
+	}
+}
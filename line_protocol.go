@@ -0,0 +1,211 @@
+package influxqu
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+func appendEscaped(dst []byte, s string, escapeEqual bool) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\n':
+			dst = append(dst, `\\n`...)
+		case '\r':
+			dst = append(dst, `\\r`...)
+		case '\t':
+			dst = append(dst, `\\t`...)
+		case ' ', ',':
+			dst = append(dst, '\\', c)
+		case '=':
+			if escapeEqual {
+				dst = append(dst, '\\')
+			}
+
+			dst = append(dst, c)
+		default:
+			dst = append(dst, c)
+		}
+	}
+
+	return dst
+}
+
+func appendMeasurement(dst []byte, s string) []byte {
+	return appendEscaped(dst, s, false)
+}
+
+func appendKey(dst []byte, s string) []byte {
+	return appendEscaped(dst, s, true)
+}
+
+func appendStringValue(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			dst = append(dst, '\\')
+		}
+
+		dst = append(dst, s[i])
+	}
+
+	return append(dst, '"')
+}
+
+func appendFloatValue(dst []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, &UnSupportedType{}
+	}
+
+	return strconv.AppendFloat(dst, f, 'g', -1, 64), nil
+}
+
+// appendFieldValue writes v with the line protocol type suffix, values are converted as write.Point does
+func appendFieldValue(dst []byte, v interface{}) ([]byte, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return appendStringValue(dst, t.Format(time.RFC3339Nano)), nil
+	case time.Duration:
+		return appendStringValue(dst, t.String()), nil
+	case []byte:
+		return appendStringValue(dst, string(t)), nil
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return append(strconv.AppendInt(dst, rv.Int(), 10), 'i'), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return append(strconv.AppendUint(dst, rv.Uint(), 10), 'u'), nil
+	case reflect.Float32, reflect.Float64:
+		return appendFloatValue(dst, rv.Float())
+	case reflect.Bool:
+		return strconv.AppendBool(dst, rv.Bool()), nil
+	case reflect.String:
+		return appendStringValue(dst, rv.String()), nil
+	}
+
+	return nil, &UnSupportedType{}
+}
+
+func appendTimestamp(dst []byte, ts time.Time, precision time.Duration) []byte {
+	switch precision {
+	case time.Second:
+		return strconv.AppendInt(dst, ts.Unix(), 10)
+	case time.Millisecond:
+		return strconv.AppendInt(dst, ts.UnixMilli(), 10)
+	case time.Microsecond:
+		return strconv.AppendInt(dst, ts.UnixMicro(), 10)
+	}
+
+	return strconv.AppendInt(dst, ts.UnixNano(), 10)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// appendLine writes one line, tags with an empty value and nil fields are left out
+func appendLine(
+	dst []byte,
+	measurement string,
+	tags map[string]string,
+	fields map[string]interface{},
+	ts time.Time,
+	precision time.Duration,
+) ([]byte, error) {
+	dst = appendMeasurement(dst, measurement)
+
+	for _, k := range sortedKeys(tags) {
+		if tags[k] == "" {
+			continue
+		}
+
+		dst = append(dst, ',')
+		dst = appendKey(dst, k)
+		dst = append(dst, '=')
+		dst = appendKey(dst, tags[k])
+	}
+
+	sep := byte(' ')
+
+	for _, k := range sortedKeys(fields) {
+		if fields[k] == nil {
+			continue
+		}
+
+		var err error
+
+		dst = append(dst, sep)
+		dst = appendKey(dst, k)
+		dst = append(dst, '=')
+
+		if dst, err = appendFieldValue(dst, fields[k]); err != nil {
+			return nil, err
+		}
+
+		sep = ','
+	}
+
+	if sep == ' ' {
+		return nil, &NoValidField{}
+	}
+
+	dst = append(dst, ' ')
+	dst = appendTimestamp(dst, ts, precision)
+
+	return append(dst, '\n'), nil
+}
+
+func (q *influxQu) appendLineProtocol(dst []byte, v any, precision time.Duration) ([]byte, error) {
+	if a, ok := v.(LineProtocolAppender); ok && !q.hasDefaults() && q.generatedFor(v) {
+		return a.AppendLineProtocol(dst, precision)
+	}
+
+	if reflect.Indirect(reflect.ValueOf(v)).Kind() == reflect.Slice {
+		elems, err := sliceElements(v)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, elem := range elems {
+			if dst, err = q.appendLineProtocol(dst, elem, precision); err != nil {
+				return nil, &ElementError{index: i, err: err}
+			}
+		}
+
+		return dst, nil
+	}
+
+	m, t, f, tp, err := q.generateCommonPointInfo(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendLine(dst, m, t, f, tp, precision)
+}
+
+func (q *influxQu) MarshalLineProtocol(v any, precision time.Duration) ([]byte, error) {
+	switch precision {
+	case time.Nanosecond, time.Microsecond, time.Millisecond, time.Second:
+	default:
+		return nil, &UnSupportedPrecision{}
+	}
+
+	return q.appendLineProtocol(nil, v, precision)
+}
+
+func (q *influxQu) AppendLineProtocol(dst []byte, v any) ([]byte, error) {
+	return q.appendLineProtocol(dst, v, time.Nanosecond)
+}
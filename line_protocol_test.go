@@ -0,0 +1,261 @@
+package influxqu
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/shopspring/decimal"
+)
+
+func Test_MarshalLineProtocol_Simple_Struct(t *testing.T) {
+	type Data struct {
+		Base      string          `influxqu:"measurement"`
+		T2        string          `influxqu:"tag,t2"`
+		T1        string          `influxqu:"tag,t1"`
+		F1        int             `influxqu:"field,f1"`
+		F2        bool            `influxqu:"field,f2"`
+		F3        decimal.Decimal `influxqu:"field,f3"`
+		F4        uint32          `influxqu:"field,f4"`
+		F5        string          `influxqu:"field,f5"`
+		Timestamp time.Time       `influxqu:"timestamp"`
+	}
+
+	g := NewinfluxQu()
+	data := Data{
+		Base:      "base",
+		T1:        "t1",
+		T2:        "t2",
+		F1:        -1,
+		F2:        true,
+		F3:        decimal.NewFromFloat(1.35),
+		F4:        7,
+		F5:        "s",
+		Timestamp: time.Unix(1, 500),
+	}
+
+	b, e := g.MarshalLineProtocol(&data, time.Nanosecond)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	expected := "base,t1=t1,t2=t2 f1=-1i,f2=true,f3=1.35,f4=7u,f5=\"s\" 1000000500\n"
+	if string(b) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected: %q", b, expected)
+	}
+
+	p, e := g.GenerateInfluxPoint(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if lp := write.PointToLineProtocol(p, time.Nanosecond); lp != string(b) {
+		t.Errorf("line protocol does not match write.Point, got: %q, expected: %q", b, lp)
+	}
+
+	b, e = g.MarshalLineProtocol(&data, time.Second)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if expected := "base,t1=t1,t2=t2 f1=-1i,f2=true,f3=1.35,f4=7u,f5=\"s\" 1\n"; string(b) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected: %q", b, expected)
+	}
+
+	if _, e := g.MarshalLineProtocol(&data, time.Minute); e == nil {
+		t.Error("expected an error for unsupported precision")
+	}
+}
+
+func Test_MarshalLineProtocol_Unsigned(t *testing.T) {
+	type Counter uint32
+
+	type Data struct {
+		Base    string  `influxqu:"measurement"`
+		Host    string  `influxqu:"tag,host"`
+		Total   uint64  `influxqu:"field,total"`
+		Errors  Counter `influxqu:"field,errors"`
+		Dropped int64   `influxqu:"field,dropped,type=uint"`
+	}
+
+	g := NewinfluxQu()
+	data := Data{Base: "base", Host: "h", Total: math.MaxUint64, Errors: 3, Dropped: 5}
+
+	b, e := g.MarshalLineProtocol(&data, time.Second)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	p, e := g.GenerateInfluxPoint(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if lp := write.PointToLineProtocol(p, time.Second); lp != string(b) {
+		t.Errorf("line protocol does not match write.Point, got: %q, expected: %q", b, lp)
+	}
+
+	if !strings.HasPrefix(string(b), "base,host=h dropped=5u,errors=3u,total=18446744073709551615u ") {
+		t.Errorf("line protocol is not expected, got: %q", b)
+	}
+
+	p3, e := g.GenerateInfluxPointV3(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if u := p3.GetUIntegerField("total"); u == nil || *u != math.MaxUint64 {
+		t.Errorf("v3 point total is not an unsigned integer: %v", p3.GetField("total"))
+	}
+
+	if u := p3.GetUIntegerField("dropped"); u == nil || *u != 5 {
+		t.Errorf("v3 point dropped is not an unsigned integer: %v", p3.GetField("dropped"))
+	}
+
+	data.Dropped = -1
+	if _, e := g.MarshalLineProtocol(&data, time.Second); e == nil {
+		t.Error("expected an error for a negative unsigned field")
+	}
+}
+
+func Test_MarshalLineProtocol_Named_Types(t *testing.T) {
+	type (
+		Celsius float64
+		Level   int8
+		State   string
+		Flag    bool
+	)
+
+	type Data struct {
+		Base    string             `influxqu:"measurement"`
+		Host    string             `influxqu:"tag,host"`
+		At      time.Time          `influxqu:"timestamp"`
+		Temp    Celsius            `influxqu:"field,c"`
+		Level   Level              `influxqu:"field,level"`
+		State   State              `influxqu:"field,state"`
+		Flag    Flag               `influxqu:"field,flag"`
+		Elapsed time.Duration      `influxqu:"field,elapsed"`
+		Values  map[string]Celsius `influxqu:"fields"`
+	}
+
+	// default tags turn off the generated methods, the reflection paths must still agree
+	for _, g := range []InfluxQu{NewinfluxQu(), NewinfluxQu(WithDefaultTags(map[string]string{"app": "a"}))} {
+		data := Data{Base: "base", Host: "h", At: time.Unix(1, 0), Temp: 21.5, Level: 2, State: "on", Flag: true, Elapsed: time.Minute, Values: map[string]Celsius{"d": 1.5}}
+
+		b, e := g.MarshalLineProtocol(&data, time.Second)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		p, e := g.GenerateInfluxPoint(&data)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		if lp := write.PointToLineProtocol(p, time.Second); lp != string(b) {
+			t.Errorf("line protocol does not match write.Point, got: %q, expected: %q", b, lp)
+		}
+
+		if !strings.Contains(string(b), ` c=21.5,d=1.5,elapsed="1m0s",flag=true,level=2i,state="on" 1`) {
+			t.Errorf("line protocol is not expected, got: %q", b)
+		}
+
+		p3, e := g.GenerateInfluxPointV3(&data)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		if f := p3.GetDoubleField("c"); f == nil || *f != 21.5 {
+			t.Errorf("v3 point c is not a float: %v", p3.GetField("c"))
+		}
+	}
+}
+
+func Test_MarshalLineProtocol_Escape(t *testing.T) {
+	type Data struct {
+		Base      string    `influxqu:"measurement"`
+		T1        string    `influxqu:"tag,t 1"`
+		T2        string    `influxqu:"tag,t2,omitempty"`
+		F1        string    `influxqu:"field,f=1"`
+		F2        *float64  `influxqu:"field,f2"`
+		Timestamp time.Time `influxqu:"timestamp"`
+	}
+
+	g := NewinfluxQu()
+	data := Data{
+		Base:      "my base,1",
+		T1:        "a=b,c d",
+		F1:        `say "hi" \o/`,
+		Timestamp: time.Unix(0, 1),
+	}
+
+	b, e := g.MarshalLineProtocol(&data, time.Nanosecond)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	expected := `my\ base\,1,t\ 1=a\=b\,c\ d f\=1="say \"hi\" \\o/" 1` + "\n"
+	if string(b) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected: %q", b, expected)
+	}
+}
+
+func Test_AppendLineProtocol_Slice(t *testing.T) {
+	type Data struct {
+		Base      string    `influxqu:"measurement"`
+		F1        float64   `influxqu:"field,f1"`
+		Timestamp time.Time `influxqu:"timestamp"`
+	}
+
+	g := NewinfluxQu()
+	data := []Data{
+		{Base: "base", F1: 1.5, Timestamp: time.Unix(0, 1)},
+		{Base: "base", F1: 2, Timestamp: time.Unix(0, 2)},
+	}
+
+	b, e := g.AppendLineProtocol([]byte("# header\n"), data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	expected := "# header\nbase f1=1.5 1\nbase f1=2 2\n"
+	if string(b) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected: %q", b, expected)
+	}
+
+	_, e = g.AppendLineProtocol(nil, []*Data{&data[0], {Base: ""}})
+
+	var elemErr *ElementError
+	if !errors.As(e, &elemErr) || elemErr.Index() != 1 {
+		t.Errorf("expected an element error at index 1, got: %v", e)
+	}
+}
+
+func Benchmark_MarshalLineProtocol(b *testing.B) {
+	type Data struct {
+		Base      string    `influxqu:"measurement"`
+		T1        string    `influxqu:"tag,t1"`
+		T2        string    `influxqu:"tag,t2,omitempty"`
+		F1        int       `influxqu:"field,f1"`
+		F2        float64   `influxqu:"field,f2"`
+		F3        string    `influxqu:"field,f3"`
+		Timestamp time.Time `influxqu:"timestamp"`
+	}
+
+	g := NewinfluxQu()
+	data := Data{Base: "base", T1: "t1", T2: "t2", F1: 1, F2: 1.5, F3: "f3", Timestamp: time.Now()}
+	buf := make([]byte, 0, 256)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var e error
+		if buf, e = g.AppendLineProtocol(buf[:0], &data); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
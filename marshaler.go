@@ -0,0 +1,118 @@
+package influxqu
+
+import (
+	"reflect"
+	"time"
+)
+
+// The interfaces below let a type control how it is written to and read from InfluxDB.
+
+type InfluxTagMarshaler interface {
+	MarshalInfluxTag() (string, error)
+}
+
+type InfluxTagUnmarshaler interface {
+	UnmarshalInfluxTag(s string) error
+}
+
+// InfluxFieldMarshaler returns the field value to write, a nil value leaves the field out
+type InfluxFieldMarshaler interface {
+	MarshalInfluxField() (any, error)
+}
+
+type InfluxFieldUnmarshaler interface {
+	UnmarshalInfluxField(v any) error
+}
+
+// InfluxPointMarshaler replaces the struct tags of a type, a zero ts is the current time
+type InfluxPointMarshaler interface {
+	MarshalInfluxPoint() (measurement string, tags map[string]string, fields map[string]any, ts time.Time, err error)
+}
+
+var (
+	tagMarshalerType     = reflect.TypeOf((*InfluxTagMarshaler)(nil)).Elem()
+	tagUnmarshalerType   = reflect.TypeOf((*InfluxTagUnmarshaler)(nil)).Elem()
+	fieldMarshalerType   = reflect.TypeOf((*InfluxFieldMarshaler)(nil)).Elem()
+	fieldUnmarshalerType = reflect.TypeOf((*InfluxFieldUnmarshaler)(nil)).Elem()
+)
+
+// customTypes reports whether the tag or field t implements the marshaler and unmarshaler of its role
+func customTypes(role fieldRole, t reflect.Type) (marshaler bool, unmarshaler bool) {
+	pt := reflect.PointerTo(t)
+
+	switch role {
+	case roleTag:
+		return pt.Implements(tagMarshalerType), pt.Implements(tagUnmarshalerType)
+	case roleField:
+		return pt.Implements(fieldMarshalerType), pt.Implements(fieldUnmarshalerType)
+	}
+
+	return false, false
+}
+
+// implementer returns f as T, through a pointer when only the pointer type implements T
+func implementer[T any](f reflect.Value) T {
+	if t, ok := f.Interface().(T); ok {
+		return t
+	}
+
+	if !f.CanAddr() {
+		p := reflect.New(f.Type())
+		p.Elem().Set(f)
+
+		return p.Interface().(T)
+	}
+
+	return f.Addr().Interface().(T)
+}
+
+func marshalTag(f reflect.Value) (string, error) {
+	return implementer[InfluxTagMarshaler](f).MarshalInfluxTag()
+}
+
+func marshalField(pf *planField, org map[string]interface{}, f reflect.Value) error {
+	v, err := implementer[InfluxFieldMarshaler](f).MarshalInfluxField()
+	if err != nil {
+		return err
+	}
+
+	if v != nil {
+		org[pf.name] = v
+	}
+
+	return nil
+}
+
+// marshalPoint reads the point of an InfluxPointMarshaler, it is validated like a tagged struct
+func marshalPoint(m InfluxPointMarshaler) (string, map[string]string, map[string]any, time.Time, error) {
+	measurement, tags, fields, ts, err := m.MarshalInfluxPoint()
+	if err != nil {
+		return "", nil, nil, time.Time{}, err
+	}
+
+	if measurement == "" {
+		return "", nil, nil, time.Time{}, &NoValidMeasurement{}
+	}
+
+	// the fields of the method are copied, so dropping the nil values leaves the caller's map alone
+	values := make(map[string]any, len(fields))
+	for k, v := range fields {
+		if v != nil {
+			values[k] = v
+		}
+	}
+
+	if len(values) == 0 {
+		return "", nil, nil, time.Time{}, &NoValidField{}
+	}
+
+	if tags == nil {
+		tags = map[string]string{}
+	}
+
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	return measurement, tags, values, ts, nil
+}
@@ -0,0 +1,135 @@
+package influxqu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+)
+
+type cents int64
+
+func (c cents) MarshalInfluxField() (any, error) {
+	return float64(c) / 100, nil
+}
+
+func (c *cents) UnmarshalInfluxField(v any) error {
+	f, ok := v.(float64)
+	if !ok {
+		return fmt.Errorf("unexpected %T", v)
+	}
+
+	*c = cents(f * 100)
+
+	return nil
+}
+
+type coordinates struct {
+	Lat, Lon float64
+}
+
+func (c *coordinates) MarshalInfluxField() (any, error) {
+	return fmt.Sprintf("%g,%g", c.Lat, c.Lon), nil
+}
+
+type severity int
+
+func (s severity) MarshalInfluxTag() (string, error) {
+	if s > 1 {
+		return "", errors.New("unknown severity")
+	}
+
+	return [...]string{"info", "error"}[s], nil
+}
+
+func (s *severity) UnmarshalInfluxTag(v string) error {
+	if v == "error" {
+		*s = 1
+	}
+
+	return nil
+}
+
+type custom struct {
+	Base     string      `influxqu:"measurement"`
+	Severity severity    `influxqu:"tag,severity"`
+	Price    cents       `influxqu:"field,price"`
+	Refund   *cents      `influxqu:"field,refund,omitempty"`
+	Position coordinates `influxqu:"field,position"`
+	At       time.Time   `influxqu:"timestamp"`
+}
+
+type customPoint struct {
+	Host  string
+	Usage float64
+}
+
+func (c customPoint) MarshalInfluxPoint() (string, map[string]string, map[string]any, time.Time, error) {
+	return "cpu", map[string]string{"host": c.Host}, map[string]any{"usage": c.Usage}, time.Unix(1, 0), nil
+}
+
+func Test_Custom_Marshalers(t *testing.T) {
+	g := NewinfluxQu()
+	data := custom{
+		Base:     "base",
+		Severity: 1,
+		Price:    1250,
+		Position: coordinates{Lat: 1.5, Lon: -2},
+		At:       time.Unix(1, 0),
+	}
+
+	b, e := g.MarshalLineProtocol(data, time.Second)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if expected := "base,severity=error position=\"1.5,-2\",price=12.5 1\n"; string(b) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected: %q", b, expected)
+	}
+
+	data.Severity = 2
+	if _, e := g.GenerateInfluxPoint(&data); e == nil || e.Error() != "unknown severity" {
+		t.Errorf("expected the marshaler error, got: %v", e)
+	}
+
+	b, e = g.MarshalLineProtocol(customPoint{Host: "h", Usage: 0.5}, time.Second)
+	if e != nil || string(b) != "cpu,host=h usage=0.5 1\n" {
+		t.Errorf("line protocol is not expected, got: %q, %v", b, e)
+	}
+
+	if _, e := g.GenerateInfluxPointV3(customPoint{Host: "h"}); e != nil {
+		t.Error(e)
+	}
+}
+
+func Test_Custom_Unmarshalers(t *testing.T) {
+	g := NewinfluxQu()
+
+	var data custom
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{
+		"severity": "error",
+		"price":    12.5,
+		"refund":   1.25,
+	}), &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Severity != 1 || data.Price != 1250 || data.Refund == nil || *data.Refund != 125 {
+		t.Errorf("decoded data is not expected, got: %+v", data)
+	}
+
+	type Row struct {
+		Value cents `influxqu:"field,f2"`
+	}
+
+	var rows []Row
+	if e := g.DecodeArrowRecord(newArrowRecord(t, 2), &rows); e != nil {
+		t.Fatal(e)
+	}
+
+	if rows[1].Value != 50 {
+		t.Errorf("arrow column is not decoded by the unmarshaler, got: %+v", rows)
+	}
+}
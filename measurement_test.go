@@ -0,0 +1,98 @@
+package influxqu
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+)
+
+type namedDisk struct {
+	Device string `influxqu:"tag,device"`
+	Free   int    `influxqu:"field,free"`
+}
+
+func (d *namedDisk) InfluxMeasurement() string {
+	return "disk"
+}
+
+func Test_Static_Measurement(t *testing.T) {
+	type CPU struct {
+		_     struct{} `influxqu:"measurement,name=cpu"`
+		Host  string   `influxqu:"tag,host"`
+		Usage float64  `influxqu:"field,usage"`
+	}
+
+	g := NewinfluxQu()
+
+	p, e := g.GenerateInfluxPoint(&CPU{Host: "h", Usage: 0.5})
+	if e != nil || p.Name() != "cpu" {
+		t.Fatalf("measurement is not expected, got: %v, %v", p, e)
+	}
+
+	p, e = g.GenerateInfluxPoint(&namedDisk{Device: "sda", Free: 1})
+	if e != nil || p.Name() != "disk" {
+		t.Fatalf("measurement is not expected, got: %v, %v", p, e)
+	}
+
+	query, _, e := g.GenerateFluxQuery("bucket", "-1h", "", &CPU{}, nil)
+	if e != nil || !strings.Contains(query, `r["_measurement"] == "cpu"`) {
+		t.Errorf("query is not expected, got: %s, %v", query, e)
+	}
+
+	// the method has a pointer receiver, a value must use it too
+	p, e = g.GenerateInfluxPoint(namedDisk{Device: "sda", Free: 1})
+	if e != nil || p.Name() != "disk" {
+		t.Errorf("measurement of a value is not expected, got: %v, %v", p, e)
+	}
+
+	query, _, e = g.GenerateFluxQuery("bucket", "-1h", "", namedDisk{Free: 1}, nil)
+	if e != nil || !strings.Contains(query, `r["_measurement"] == "disk"`) {
+		t.Errorf("query of a value is not expected, got: %s, %v", query, e)
+	}
+
+	type Duplicated struct {
+		_    struct{} `influxqu:"measurement,name=cpu"`
+		Name string   `influxqu:"measurement"`
+		F1   int      `influxqu:"field,f1"`
+	}
+
+	if _, e := g.GenerateInfluxPoint(&Duplicated{F1: 1}); e == nil {
+		t.Error("expected a duplicated measurement error")
+	}
+
+	type BadName struct {
+		Base string `influxqu:"measurement,name=cpu"`
+		F1   int    `influxqu:"field,f1"`
+	}
+
+	if _, e := g.GenerateInfluxPoint(&BadName{F1: 1}); e == nil {
+		t.Error("expected an unsupported tag error for name on a string field")
+	}
+}
+
+func Test_Decode_Static_Measurement(t *testing.T) {
+	type CPU struct {
+		_     struct{}  `influxqu:"measurement,name=cpu"`
+		Host  string    `influxqu:"tag,host"`
+		Usage float64   `influxqu:"field,usage"`
+		At    time.Time `influxqu:"timestamp"`
+	}
+
+	g := NewinfluxQu()
+
+	var data CPU
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{
+		"_measurement": "cpu",
+		"_time":        time.Unix(1, 0),
+		"host":         "h",
+		"usage":        0.5,
+	}), &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Host != "h" || data.Usage != 0.5 || !data.At.Equal(time.Unix(1, 0)) {
+		t.Errorf("decoded data is not expected, got: %+v", data)
+	}
+}
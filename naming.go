@@ -0,0 +1,88 @@
+package influxqu
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NamingStrategy derives the name of a tag or field whose struct tag has no name, influxqu:"field"
+type NamingStrategy int
+
+const (
+	NamingRequired  NamingStrategy = iota // the struct tag must name the tag or field
+	NamingAsIs                            // CPUUsage
+	NamingSnakeCase                       // cpu_usage
+	NamingCamelCase                       // cpuUsage
+	NamingLowerCase                       // cpuusage
+)
+
+// WithNamingStrategy derives missing tag and field names from the Go field names
+func WithNamingStrategy(s NamingStrategy) Option {
+	return func(q *influxQu) {
+		q.naming = s
+	}
+}
+
+// Name returns the tag or field name of the Go field name, NamingRequired returns ""
+func (s NamingStrategy) Name(goName string) string {
+	switch s {
+	case NamingAsIs:
+		return goName
+	case NamingSnakeCase:
+		return strings.ToLower(strings.Join(splitWords(goName), "_"))
+	case NamingCamelCase:
+		words := splitWords(goName)
+		for i := range words {
+			words[i] = strings.ToLower(words[i])
+			if r, size := utf8.DecodeRuneInString(words[i]); i > 0 && size > 0 {
+				words[i] = string(unicode.ToUpper(r)) + words[i][size:]
+			}
+		}
+
+		return strings.Join(words, "")
+	case NamingLowerCase:
+		return strings.ToLower(goName)
+	}
+
+	return ""
+}
+
+// splitWords splits a Go name into words, an upper case run is one word, HTTPServer2xx is HTTP Server2xx
+func splitWords(name string) []string {
+	runes := []rune(name)
+	words := make([]string, 0, 4)
+	start := 0
+
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			if runes[i] == '_' {
+				words = append(words, string(runes[start:i]))
+				start = i + 1
+			}
+
+			continue
+		}
+
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	words = append(words, string(runes[start:]))
+
+	// drop the empty words left by leading, trailing or doubled underscores
+	n := 0
+	for _, w := range words {
+		if w != "" {
+			words[n] = w
+			n++
+		}
+	}
+
+	return words[:n]
+}
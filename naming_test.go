@@ -0,0 +1,60 @@
+package influxqu
+
+import (
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func Test_NamingStrategy_Name(t *testing.T) {
+	cases := map[string][4]string{
+		"CPUUsage":      {"CPUUsage", "cpu_usage", "cpuUsage", "cpuusage"},
+		"HTTPServer2xx": {"HTTPServer2xx", "http_server2xx", "httpServer2xx", "httpserver2xx"},
+		"ID":            {"ID", "id", "id", "id"},
+		"userID":        {"userID", "user_id", "userId", "userid"},
+		"Disk_Free":     {"Disk_Free", "disk_free", "diskFree", "disk_free"},
+		"FooÄbc":        {"FooÄbc", "foo_äbc", "fooÄbc", "fooäbc"},
+		"Size_ölLevel":  {"Size_ölLevel", "size_öl_level", "sizeÖlLevel", "size_öllevel"},
+	}
+
+	for name, expected := range cases {
+		for i, s := range []NamingStrategy{NamingAsIs, NamingSnakeCase, NamingCamelCase, NamingLowerCase} {
+			if got := s.Name(name); got != expected[i] || !utf8.ValidString(got) {
+				t.Errorf("name of %s is not expected, got: %s, expected: %s", name, got, expected[i])
+			}
+		}
+	}
+
+	if NamingRequired.Name("CPUUsage") != "" {
+		t.Error("NamingRequired must not derive a name")
+	}
+}
+
+func Test_NamingStrategy_Plan(t *testing.T) {
+	type Reading struct {
+		Value float64 `influxqu:"field"`
+	}
+
+	type Data struct {
+		Base     string  `influxqu:"measurement"`
+		HostName string  `influxqu:"tag"`
+		CPUUsage float64 `influxqu:"field,,omitempty"`
+		Load     int     `influxqu:"field,load_avg"`
+		Temp     Reading `influxqu:"inline,prefix=temp_"`
+	}
+
+	g := NewinfluxQu(WithNamingStrategy(NamingSnakeCase))
+
+	b, e := g.MarshalLineProtocol(&Data{Base: "base", HostName: "h", CPUUsage: 0.5, Load: 1, Temp: Reading{Value: 2}}, time.Second)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if expected := "base,host_name=h cpu_usage=0.5,load_avg=1i,temp_value=2 "; string(b[:len(expected)]) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected prefix: %q", b, expected)
+	}
+
+	if _, e := NewinfluxQu().GenerateInfluxPoint(&Data{Base: "base"}); e == nil {
+		t.Error("expected a no tag name error without a naming strategy")
+	}
+}
@@ -0,0 +1,60 @@
+package influxqu
+
+// Option configures an InfluxQu instance
+type Option func(q *influxQu)
+
+// WithDefaultTags adds tags to every point and query, a tag declared by the structure overrides the default
+func WithDefaultTags(tags map[string]string) Option {
+	return func(q *influxQu) {
+		if q.defaultTags == nil {
+			q.defaultTags = make(map[string]string, len(tags))
+		}
+
+		for k, v := range tags {
+			q.defaultTags[k] = v
+		}
+	}
+}
+
+// WithMeasurementPrefix prefixes every measurement, decoding removes the prefix again
+func WithMeasurementPrefix(prefix string) Option {
+	return func(q *influxQu) {
+		q.measurementPrefix = prefix
+	}
+}
+
+// WithStrictDefaults returns a DuplicatedTag error instead of overriding a default tag
+func WithStrictDefaults() Option {
+	return func(q *influxQu) {
+		q.strictDefaults = true
+	}
+}
+
+// hasDefaults reports whether points differ from the ones the generated methods build
+func (q *influxQu) hasDefaults() bool {
+	return len(q.defaultTags) != 0 || q.measurementPrefix != ""
+}
+
+// applyDefaults adds the default tags missing from tags and declared, and prefixes the measurement
+func (q *influxQu) applyDefaults(measurement string, tags map[string]string, declared map[string]struct{}) (string, error) {
+	for k, v := range q.defaultTags {
+		_, isTag := tags[k]
+		_, isDeclared := declared[k]
+
+		if isTag || isDeclared {
+			if q.strictDefaults {
+				return "", &DuplicatedTag{tag: k}
+			}
+
+			continue
+		}
+
+		tags[k] = v
+	}
+
+	if measurement != "" {
+		measurement = q.measurementPrefix + measurement
+	}
+
+	return measurement, nil
+}
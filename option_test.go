@@ -0,0 +1,124 @@
+package influxqu
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+)
+
+func Test_Options_Defaults(t *testing.T) {
+	type Data struct {
+		Base   string            `influxqu:"measurement"`
+		Region string            `influxqu:"tag,region,omitempty"`
+		F1     int               `influxqu:"field,f1"`
+		Labels map[string]string `influxqu:"tags"`
+		At     time.Time         `influxqu:"timestamp"`
+	}
+
+	g := NewinfluxQu(
+		WithDefaultTags(map[string]string{"host": "h1", "region": "eu", "service": "api"}),
+		WithMeasurementPrefix("app_"),
+	)
+	data := Data{Base: "cpu", Region: "us", F1: 1, At: time.Unix(1, 0)}
+
+	b, e := g.MarshalLineProtocol(&data, time.Second)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if expected := "app_cpu,host=h1,region=us,service=api f1=1i 1\n"; string(b) != expected {
+		t.Errorf("line protocol is not expected, got: %q, expected: %q", b, expected)
+	}
+
+	p, e := g.GenerateInfluxPointV3(&data)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if service, _ := p.GetTag("service"); p.GetMeasurement() != "app_cpu" || service != "api" {
+		t.Errorf("point is not expected, got: %v, %v", p, e)
+	}
+
+	query, _, e := g.GenerateFluxQuery("bucket", "-1h", "", &Data{Base: "cpu"}, nil)
+	if e != nil || !strings.Contains(query, `r["_measurement"] == "app_cpu"`) || !strings.Contains(query, `r["host"] == "h1"`) ||
+		strings.Contains(query, `r["region"]`) {
+		t.Errorf("query is not expected, got: %s, %v", query, e)
+	}
+
+	strict := NewinfluxQu(WithDefaultTags(map[string]string{"region": "eu"}), WithStrictDefaults())
+	if _, e := strict.GenerateInfluxPoint(&data); e == nil || e.Error() != "duplicated tag region" {
+		t.Errorf("expected a duplicated tag error, got: %v", e)
+	}
+
+	type Other struct {
+		Base   string            `influxqu:"measurement"`
+		F1     int               `influxqu:"field,f1"`
+		Labels map[string]string `influxqu:"tags"`
+	}
+
+	if _, e := strict.GenerateInfluxPoint(&Other{Base: "cpu", F1: 1, Labels: map[string]string{"region": "us"}}); e == nil {
+		t.Error("expected a duplicated tag error for a dynamic tag")
+	}
+
+	if p, e := strict.GenerateInfluxPoint(&Other{Base: "cpu", F1: 1}); e != nil || len(p.TagList()) != 1 {
+		t.Errorf("default tag is not added, got: %v, %v", p, e)
+	}
+}
+
+func Test_Options_Decode_Prefix(t *testing.T) {
+	type Data struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+	}
+
+	g := NewinfluxQu(WithMeasurementPrefix("app_"))
+
+	var data Data
+	if e := g.DecodeFluxRecord(query.NewFluxRecord(0, map[string]interface{}{
+		"_measurement": "app_cpu",
+		"f1":           int64(1),
+	}), &data); e != nil {
+		t.Fatal(e)
+	}
+
+	if data.Base != "cpu" || data.F1 != 1 {
+		t.Errorf("decoded data is not expected, got: %+v", data)
+	}
+
+	for _, typ := range []arrow.DataType{
+		arrow.BinaryTypes.String,
+		&arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String},
+	} {
+		b := array.NewRecordBuilder(memory.NewGoAllocator(), arrow.NewSchema([]arrow.Field{
+			{Name: "iox::measurement", Type: typ},
+			{Name: "f1", Type: arrow.PrimitiveTypes.Int64},
+		}, nil))
+
+		switch mb := b.Field(0).(type) {
+		case *array.StringBuilder:
+			mb.Append("app_cpu")
+		case *array.BinaryDictionaryBuilder:
+			if e := mb.AppendString("app_cpu"); e != nil {
+				t.Fatal(e)
+			}
+		}
+
+		b.Field(1).(*array.Int64Builder).Append(1)
+
+		var rows []Data
+		if e := g.DecodeArrowRecord(b.NewRecordBatch(), &rows); e != nil {
+			t.Fatal(e)
+		}
+
+		b.Release()
+
+		if len(rows) != 1 || rows[0].Base != "cpu" || rows[0].F1 != 1 {
+			t.Errorf("decoded arrow data is not expected, got: %+v", rows)
+		}
+	}
+}
@@ -0,0 +1,598 @@
+package influxqu
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type fieldRole int
+
+type decimalMode int
+
+const (
+	decimalFloat decimalMode = iota
+	decimalString
+	decimalScaled
+)
+
+const (
+	roleMeasurement fieldRole = iota
+	roleTag
+	roleField
+	roleTimestamp
+)
+
+// planField is a tagged struct field resolved once per type
+type planField struct {
+	role      fieldRole
+	index     []int
+	name      string
+	omitempty bool
+	kind      reflect.Kind // kind of the field, pointers are dereferenced
+	isPtr     bool
+	isDecimal bool
+	unsigned  bool // written as an unsigned integer
+
+	// marshaler and unmarshaler are set when the type implements the Influx(Tag|Field)(Un)marshaler of the role
+	marshaler   bool
+	unmarshaler bool
+
+	decimalMode  decimalMode
+	decimalScale int32
+
+	// an expanded slice or array field writes its elements to expandPrefix + index + expandSuffix,
+	// expandLen is the length of the array or maxExpandLen
+	expand       bool
+	expandPrefix string
+	expandSuffix string
+	expandLen    int
+
+	// unixUnit and layout convert an integer or string timestamp field
+	unixUnit time.Duration
+	layout   string
+}
+
+type typePlan struct {
+	fields []planField
+
+	// tagMap and fieldMap are the map members expanded into dynamic tags and fields
+	tagMap     *planField
+	fieldMap   *planField
+	tagNames   map[string]struct{}
+	fieldNames map[string]struct{}
+
+	hasExpand bool
+
+	// measurement is the static name of measurement,name=cpu, measurer is set when the type implements InfluxMeasurer
+	measurement string
+	measurer    bool
+}
+
+type planState struct {
+	measurement bool
+	timestamp   bool
+	tags        map[string]struct{}
+	fields      map[string]struct{}
+	inlining    map[reflect.Type]struct{} // inlined types on the current path, to reject recursive types
+
+	// Validate collects the errors of every field instead of stopping at the first one
+	collect bool
+	path    []string
+	errs    []*FieldError
+}
+
+func newPlanState(t reflect.Type) *planState {
+	return &planState{
+		tags:     make(map[string]struct{}),
+		fields:   make(map[string]struct{}),
+		inlining: map[reflect.Type]struct{}{t: {}},
+		path:     []string{typeName(t)},
+	}
+}
+
+func typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+
+	return t.String()
+}
+
+// fail records err of the field f when validating, otherwise err is returned
+func (s *planState) fail(f *reflect.StructField, tag string, err error) error {
+	if !s.collect {
+		return err
+	}
+
+	path := strings.Join(append(s.path[:len(s.path):len(s.path)], f.Name), ".")
+	s.errs = append(s.errs, &FieldError{path: path, tag: tag, err: err})
+
+	return nil
+}
+
+// enter adds the field f to the path of the fields being compiled, the returned func removes it
+func (s *planState) enter(f *reflect.StructField) func() {
+	s.path = append(s.path, f.Name)
+
+	return func() {
+		s.path = s.path[:len(s.path)-1]
+	}
+}
+
+var measurerType = reflect.TypeOf((*InfluxMeasurer)(nil)).Elem()
+
+func isDecimalType(t reflect.Type) bool {
+	return t.PkgPath() == decimalPkgPath && t.Name() == decimalStructName
+}
+
+// parseDecimalOption parses the value of decimal=float|string|scaled:N
+func parseDecimalOption(pf *planField, value string) error {
+	if !pf.isDecimal || pf.role != roleField {
+		return &UnSupportedTag{}
+	}
+
+	switch {
+	case value == decimalFloatValue:
+		pf.decimalMode = decimalFloat
+	case value == decimalStringValue:
+		pf.decimalMode = decimalString
+	case strings.HasPrefix(value, decimalScaledValue+":"):
+		n, err := strconv.ParseInt(strings.TrimPrefix(value, decimalScaledValue+":"), 10, 32)
+		if err != nil || n < 0 || n > maxDecimalScale {
+			return &UnSupportedTag{}
+		}
+
+		pf.decimalMode = decimalScaled
+		pf.decimalScale = int32(n)
+	default:
+		return &UnSupportedTag{}
+	}
+
+	return nil
+}
+
+var (
+	unixUnits = map[string]time.Duration{
+		"s":  time.Second,
+		"ms": time.Millisecond,
+		"us": time.Microsecond,
+		"ns": time.Nanosecond,
+	}
+
+	namedLayouts = map[string]string{
+		"ANSIC":       time.ANSIC,
+		"UnixDate":    time.UnixDate,
+		"RFC822":      time.RFC822,
+		"RFC822Z":     time.RFC822Z,
+		"RFC850":      time.RFC850,
+		"RFC1123":     time.RFC1123,
+		"RFC1123Z":    time.RFC1123Z,
+		"RFC3339":     time.RFC3339,
+		"RFC3339Nano": time.RFC3339Nano,
+		"DateTime":    time.DateTime,
+		"DateOnly":    time.DateOnly,
+	}
+)
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uint64
+}
+
+func parseFieldOptions(pf *planField, options []string) error {
+	for _, o := range options {
+		key, value, ok := strings.Cut(o, "=")
+
+		switch {
+		case o == omitemptyKey:
+			pf.omitempty = true
+		case ok && key == typeKey:
+			if value != typeUintValue || pf.role != roleField || (!isIntKind(pf.kind) && !isUintKind(pf.kind)) {
+				return &UnSupportedTag{}
+			}
+
+			pf.unsigned = true
+		case key == expandKey:
+			if err := parseExpandOption(pf, value, ok); err != nil {
+				return err
+			}
+		case ok && key == unixKey:
+			unit, found := unixUnits[value]
+			if !found || pf.role != roleTimestamp || (!isIntKind(pf.kind) && !isUintKind(pf.kind)) {
+				return &UnSupportedTag{}
+			}
+
+			pf.unixUnit = unit
+		case ok && key == layoutKey:
+			if value == "" || pf.role != roleTimestamp || pf.kind != reflect.String {
+				return &UnSupportedTag{}
+			}
+
+			// a layout is either the name of a time package constant or the layout itself
+			if named, found := namedLayouts[value]; found {
+				value = named
+			}
+
+			pf.layout = value
+		case ok && key == decimalKey:
+			if err := parseDecimalOption(pf, value); err != nil {
+				return err
+			}
+		default:
+			return &UnSupportedTag{}
+		}
+	}
+
+	return nil
+}
+
+// setValue decodes the column value v into the field f, applying the options of the field
+func (pf *planField) setValue(f reflect.Value, column string, v interface{}) error {
+	if pf.unmarshaler && pf.role == roleTag {
+		if s, ok := v.(string); ok {
+			if f.Kind() == reflect.Ptr {
+				f.Set(reflect.New(f.Type().Elem()))
+				f = f.Elem()
+			}
+
+			return f.Addr().Interface().(InfluxTagUnmarshaler).UnmarshalInfluxTag(s)
+		}
+	}
+
+	if (pf.unixUnit != 0 || pf.layout != "") && v != nil {
+		t, err := toTime(v)
+		if err != nil {
+			return &MismatchedType{column: column, target: f.Type(), value: v}
+		}
+
+		if pf.unixUnit != 0 {
+			v = unixValue(t, pf.unixUnit)
+		} else {
+			v = t.Format(pf.layout)
+		}
+	}
+
+	if pf.decimalMode == decimalScaled && v != nil {
+		d, err := toDecimal(v)
+		if err != nil {
+			return &MismatchedType{column: column, target: f.Type(), value: v}
+		}
+
+		v = d.Shift(-pf.decimalScale)
+	}
+
+	return setFieldValue(f, column, v)
+}
+
+// compileMap records a map[string]string tags member or a map[string]T fields member
+func compileMap(p *typePlan, f *reflect.StructField, tgs []string, index []int) error {
+	t := f.Type
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return &UnSupportedType{}
+	}
+
+	pf := &planField{index: index, kind: reflect.Map}
+
+	for _, o := range tgs[1:] {
+		if o != omitemptyKey {
+			return &UnSupportedTag{}
+		}
+
+		pf.omitempty = true
+	}
+
+	target := &p.fieldMap
+	if tgs[0] == tagsKey {
+		if t.Elem().Kind() != reflect.String {
+			return &UnSupportedType{}
+		}
+
+		target = &p.tagMap
+	}
+
+	if *target != nil {
+		return &UnSupportedTag{}
+	}
+
+	*target = pf
+
+	return nil
+}
+
+func (q *influxQu) compileField(p *typePlan, s *planState, f *reflect.StructField, index []int, prefix string) error {
+	tgs := parseTag(f.Tag.Get(q.key))
+	if tgs[0] == q.tagKey || tgs[0] == q.fieldKey {
+		if len(tgs) == 1 {
+			tgs = append(tgs, "")
+		}
+
+		if tgs[1] == "" {
+			tgs[1] = q.naming.Name(f.Name)
+		}
+
+		if tgs[1] != "" {
+			tgs[1] = prefix + tgs[1]
+		}
+	}
+
+	if tgs[0] == tagsKey || tgs[0] == fieldsKey {
+		return compileMap(p, f, tgs, index)
+	}
+
+	pf := planField{index: index}
+
+	pf.kind = f.Type.Kind()
+	if pf.kind == reflect.Ptr {
+		pf.isPtr = true
+		pf.kind = f.Type.Elem().Kind()
+		pf.isDecimal = isDecimalType(f.Type.Elem())
+	} else {
+		pf.isDecimal = isDecimalType(f.Type)
+	}
+
+	switch tgs[0] {
+	case q.measurementKey:
+		if s.measurement {
+			return &DuplicatedMeasurement{}
+		}
+
+		s.measurement = true
+
+		if len(tgs) != 1 {
+			return parseMeasurementName(p, f, tgs[1:])
+		}
+
+		pf.role = roleMeasurement
+	case q.tagKey:
+		if len(tgs) < 2 || tgs[1] == "" {
+			return &NoTagName{}
+		}
+
+		if _, ok := s.tags[tgs[1]]; ok {
+			return &DuplicatedTag{tag: tgs[1]}
+		}
+
+		s.tags[tgs[1]] = struct{}{}
+		pf.role = roleTag
+		pf.name = tgs[1]
+	case q.fieldKey:
+		if len(tgs) < 2 || tgs[1] == "" {
+			return &NoFieldName{}
+		}
+
+		if _, ok := s.fields[tgs[1]]; ok {
+			return &DuplicatedField{field: tgs[1]}
+		}
+
+		s.fields[tgs[1]] = struct{}{}
+		pf.role = roleField
+		pf.name = tgs[1]
+	case q.timestampKey:
+		if s.timestamp {
+			return &DuplicatedTimestamp{}
+		}
+
+		s.timestamp = true
+		pf.role = roleTimestamp
+	default:
+		return nil
+	}
+
+	elem := f.Type
+	if pf.isPtr {
+		elem = elem.Elem()
+	}
+
+	pf.marshaler, pf.unmarshaler = customTypes(pf.role, elem)
+	pf.unsigned = isUintKind(pf.kind) && !pf.marshaler
+
+	options := tgs[min(len(tgs), 2):]
+	if pf.role == roleTimestamp {
+		options = tgs[1:]
+	}
+
+	if err := parseFieldOptions(&pf, options); err != nil {
+		return err
+	}
+
+	if pf.expand {
+		pf.expandLen = maxExpandLen
+		if elem.Kind() == reflect.Array {
+			pf.expandLen = elem.Len()
+		}
+	}
+
+	p.hasExpand = p.hasExpand || pf.expand
+	p.fields = append(p.fields, pf)
+
+	return nil
+}
+
+// parseMeasurementName parses measurement,name=cpu, it is only allowed on a blank struct{} field
+func parseMeasurementName(p *typePlan, f *reflect.StructField, options []string) error {
+	if len(options) != 1 || f.Type.Kind() != reflect.Struct || f.Type.NumField() != 0 {
+		return &UnSupportedTag{}
+	}
+
+	key, value, ok := strings.Cut(options[0], "=")
+	if !ok || key != nameKey || value == "" {
+		return &UnSupportedTag{}
+	}
+
+	p.measurement = value
+
+	return nil
+}
+
+// parseInline returns the prefix of an inline tag, inline,prefix=cpu_
+func parseInline(options []string) (string, error) {
+	prefix := ""
+
+	for _, o := range options {
+		key, value, ok := strings.Cut(o, "=")
+		if !ok || key != prefixKey {
+			return "", &UnSupportedTag{}
+		}
+
+		prefix = value
+	}
+
+	return prefix, nil
+}
+
+// compileInline flattens the named struct field f into the plan, prefixing its tag and field names
+func (q *influxQu) compileInline(p *typePlan, s *planState, f *reflect.StructField, tgs []string, index []int, prefix string) error {
+	sub := f.Type
+	if sub.Kind() == reflect.Ptr {
+		sub = sub.Elem()
+	}
+
+	if sub.Kind() != reflect.Struct || isDecimalType(sub) || sub == reflect.TypeOf(time.Time{}) {
+		return &UnSupportedTag{}
+	}
+
+	if _, ok := s.inlining[sub]; ok {
+		return &UnSupportedTag{}
+	}
+
+	inner, err := parseInline(tgs[1:])
+	if err != nil {
+		return err
+	}
+
+	s.inlining[sub] = struct{}{}
+	defer delete(s.inlining, sub)
+	defer s.enter(f)()
+
+	return q.compilePlan(p, s, sub, index, prefix+inner)
+}
+
+func (q *influxQu) compilePlan(p *typePlan, s *planState, t reflect.Type, index []int, prefix string) error {
+	n := t.NumField()
+	for i := 0; i < n; i++ {
+		f := t.Field(i)
+		path := append(append(make([]int, 0, len(index)+1), index...), i)
+
+		tag := f.Tag.Get(q.key)
+
+		if tgs := parseTag(tag); tgs[0] == inlineKey {
+			if err := q.compileInline(p, s, &f, tgs, path, prefix); err != nil {
+				if err = s.fail(&f, tag, err); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if f.Anonymous {
+			sub := f.Type
+			if sub.Kind() == reflect.Ptr {
+				sub = sub.Elem()
+			}
+
+			if sub.Kind() == reflect.Struct {
+				// an embedded type is guarded like an inlined one, *Node in Node would never end
+				if _, ok := s.inlining[sub]; ok {
+					if err := s.fail(&f, tag, &UnSupportedTag{}); err != nil {
+						return err
+					}
+
+					continue
+				}
+
+				s.inlining[sub] = struct{}{}
+				leave := s.enter(&f)
+				err := q.compilePlan(p, s, sub, path, prefix)
+
+				leave()
+				delete(s.inlining, sub)
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if tag == "" {
+			continue
+		}
+
+		if err := q.compileField(p, s, &f, path, prefix); err != nil {
+			if err = s.fail(&f, tag, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// typePlan returns the cached plan of t, compiling it on first use
+func (q *influxQu) typePlan(t reflect.Type) (*typePlan, error) {
+	if p, ok := q.plans.Load(t); ok {
+		return p.(*typePlan), nil
+	}
+
+	p := &typePlan{}
+	s := newPlanState(t)
+
+	if err := q.compilePlan(p, s, t, nil, ""); err != nil {
+		return nil, err
+	}
+
+	p.tagNames, p.fieldNames = s.tags, s.fields
+	p.measurer = reflect.PointerTo(t).Implements(measurerType)
+
+	actual, _ := q.plans.LoadOrStore(t, p)
+
+	return actual.(*typePlan), nil
+}
+
+// staticMeasurement returns the measurement of a type without a measurement field value
+func (p *typePlan) staticMeasurement(val reflect.Value) string {
+	if p.measurer {
+		if s := implementer[InfluxMeasurer](val).InfluxMeasurement(); s != "" {
+			return s
+		}
+	}
+
+	return p.measurement
+}
+
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, x := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for _, x := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v
+}
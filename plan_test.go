@@ -0,0 +1,150 @@
+package influxqu
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func Test_TypePlan_Cached(t *testing.T) {
+	type Tag struct {
+		T2 string `influxqu:"tag,t2,omitempty"`
+	}
+
+	type Data struct {
+		*Tag
+		Base      string    `influxqu:"measurement"`
+		T1        string    `influxqu:"tag,t1"`
+		F1        *int      `influxqu:"field,f1"`
+		Timestamp time.Time `influxqu:"timestamp"`
+	}
+
+	q := NewinfluxQu().(*influxQu)
+	ty := reflect.TypeOf(Data{})
+
+	var wg sync.WaitGroup
+
+	plans := make([]*typePlan, 8)
+
+	for i := range plans {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			p, e := q.typePlan(ty)
+			if e != nil {
+				t.Error(e)
+			}
+
+			plans[i] = p
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, p := range plans[1:] {
+		if p != plans[0] {
+			t.Fatal("plan is not cached")
+		}
+	}
+
+	expected := []planField{
+		{role: roleTag, index: []int{0, 0}, name: "t2", omitempty: true, kind: reflect.String},
+		{role: roleMeasurement, index: []int{1}, kind: reflect.String},
+		{role: roleTag, index: []int{2}, name: "t1", kind: reflect.String},
+		{role: roleField, index: []int{3}, name: "f1", kind: reflect.Int, isPtr: true},
+		{role: roleTimestamp, index: []int{4}, kind: reflect.Struct},
+	}
+
+	if !reflect.DeepEqual(plans[0].fields, expected) {
+		t.Errorf("plan is not expected, got: %+v, expected: %+v", plans[0].fields, expected)
+	}
+}
+
+func Test_TypePlan_Errors(t *testing.T) {
+	type DuplicatedTags struct {
+		T1 string `influxqu:"tag,t1"`
+		T2 string `influxqu:"tag,t1,omitempty"`
+	}
+
+	type UnknownOption struct {
+		F1 int `influxqu:"field,f1,unknown"`
+	}
+
+	type DecimalOnInt struct {
+		F1 int `influxqu:"field,f1,decimal=string"`
+	}
+
+	type DecimalScale struct {
+		F1 decimal.Decimal `influxqu:"field,f1,decimal=scaled:19"`
+	}
+
+	type UintOnString struct {
+		F1 string `influxqu:"field,f1,type=uint"`
+	}
+
+	type UnixOnString struct {
+		At string `influxqu:"timestamp,unix=ms"`
+	}
+
+	type LayoutOnInt struct {
+		At int64 `influxqu:"timestamp,layout=RFC3339"`
+	}
+
+	type UnknownUnit struct {
+		At int64 `influxqu:"timestamp,unix=m"`
+	}
+
+	type Node struct {
+		Value int   `influxqu:"field,value"`
+		Next  *Node `influxqu:"inline,prefix=next_"`
+	}
+
+	type Embedded struct {
+		*Embedded
+		M string  `influxqu:"measurement"`
+		V float64 `influxqu:"field,v"`
+	}
+
+	type ExpandOnInt struct {
+		F1 int `influxqu:"field,f1,expand"`
+	}
+
+	type ExpandFormat struct {
+		F1 []int `influxqu:"field,f1,expand=%s_%x"`
+	}
+
+	type InlineInt struct {
+		F1 int `influxqu:"inline"`
+	}
+
+	q := NewinfluxQu().(*influxQu)
+
+	for _, v := range []any{Node{}, Embedded{}, InlineInt{}, ExpandOnInt{}, ExpandFormat{}, DecimalOnInt{}, DecimalScale{}, UintOnString{}, UnixOnString{}, LayoutOnInt{}, UnknownUnit{}} {
+		if _, e := q.typePlan(reflect.TypeOf(v)); e == nil {
+			t.Errorf("expected an unsupported tag error for %T", v)
+		}
+	}
+
+	if _, e := q.typePlan(reflect.TypeOf(DuplicatedTags{})); e == nil || e.Error() != "duplicated tag t1" {
+		t.Errorf("expected a duplicated tag error, got: %v", e)
+	}
+
+	if _, e := q.typePlan(reflect.TypeOf(UnknownOption{})); e == nil {
+		t.Error("expected an unsupported tag error")
+	}
+
+	if _, e := q.GenerateInfluxPoint(&Embedded{M: "m", V: 1}); !errors.Is(e, ErrUnSupportedTag) {
+		t.Errorf("expected an unsupported tag error for a recursive embedded type, got: %v", e)
+	}
+
+	var schema *SchemaError
+	if e := q.Validate(Embedded{}); !errors.As(e, &schema) || len(schema.Errors()) != 1 || schema.Errors()[0].Path() != "Embedded.Embedded" {
+		t.Errorf("expected an error on the embedded field, got: %v", e)
+	}
+}
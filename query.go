@@ -0,0 +1,37 @@
+package influxqu
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+)
+
+const fluxPivot = `pivot(rowKey:["_time"], columnKey:["_field"], valueColumn:"_value")`
+
+// defaultQu is shared by the calls of Query, so the plan of each type is compiled once
+var defaultQu = NewinfluxQu()
+
+func Query[T any](ctx context.Context, queryAPI api.QueryAPI, bucket string, rng TimeRange, filter T) ([]T, error) {
+	return QueryWith(ctx, defaultQu, queryAPI, bucket, rng, filter)
+}
+
+func QueryWith[T any](ctx context.Context, q InfluxQu, queryAPI api.QueryAPI, bucket string, rng TimeRange, filter T) ([]T, error) {
+	query, _, err := q.NewFluxQuery(bucket, filter).Range(rng).Pivot().Build()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := queryAPI.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	var data []T
+	if err := q.DecodeFluxTable(res, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
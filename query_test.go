@@ -0,0 +1,57 @@
+package influxqu
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+)
+
+type fakeQueryAPI struct {
+	api.QueryAPI
+	csv   string
+	query string
+}
+
+func (f *fakeQueryAPI) Query(_ context.Context, query string) (*api.QueryTableResult, error) {
+	f.query = query
+	return newQueryTableResult(f.csv), nil
+}
+
+func Test_Query(t *testing.T) {
+	type Data struct {
+		Base string  `influxqu:"measurement"`
+		T1   string  `influxqu:"tag,t1"`
+		F1   float64 `influxqu:"field,f1"`
+	}
+
+	queryAPI := &fakeQueryAPI{csv: `#datatype,string,long,string,string,double
+#group,false,false,true,true,false
+#default,_result,,,,
+,result,table,_measurement,t1,f1
+,,0,base,a,1.5
+,,0,base,a,2.5
+`}
+
+	data, err := Query(context.Background(), queryAPI, "bucket", LastN(time.Hour), Data{Base: "base", T1: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `from(bucket: "bucket")
+ |> range(start: -1h)`
+
+	if !strings.HasPrefix(queryAPI.query, expected) || !strings.HasSuffix(queryAPI.query, "\n |> "+fluxPivot) {
+		t.Errorf("query is not expected, got: %s", queryAPI.query)
+	}
+
+	if len(data) != 2 || data[0].F1 != 1.5 || data[1].F1 != 2.5 || data[1].T1 != "a" {
+		t.Errorf("rows are not expected, got: %+v", data)
+	}
+	if _, ok := defaultQu.(*influxQu).plans.Load(reflect.TypeOf(Data{})); !ok {
+		t.Error("plan of the type is not cached by the shared instance")
+	}
+}
@@ -0,0 +1,161 @@
+package influxqu
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
+)
+
+type sqlOptions struct {
+	binInterval time.Duration
+	aggregate   string
+}
+
+// SQLOption configures GenerateSQLQuery
+type SQLOption func(o *sqlOptions)
+
+// WithDateBin groups the rows into interval wide bins with date_bin and aggregates every field with
+// the SQL function aggregate, like avg or max
+func WithDateBin(interval time.Duration, aggregate string) SQLOption {
+	return func(o *sqlOptions) {
+		o.binInterval = interval
+		o.aggregate = aggregate
+	}
+}
+
+// sqlIdentifier quotes s as a SQL identifier
+func sqlIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func sqlInterval(d time.Duration) string {
+	n, unit := exactUnit(d, sqlUnits)
+	return "interval '" + strconv.FormatInt(n, 10) + " " + unit + "'"
+}
+
+// sqlColumns returns the declared tag and field names of p, like GenerateFluxQuery the fields
+// with a value in values narrow the selection
+func sqlColumns(p *typePlan, values map[string]interface{}) (tags []string, fields []string) {
+	for i := range p.fields {
+		pf := &p.fields[i]
+
+		switch {
+		case pf.role == roleTag:
+			tags = append(tags, pf.name)
+		case pf.role == roleField && !pf.expand:
+			fields = append(fields, pf.name)
+		}
+	}
+
+	selected := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		if v, ok := values[f]; ok && !isValueEmpty(v) {
+			selected = append(selected, f)
+		}
+	}
+
+	if len(selected) != 0 {
+		fields = selected
+	}
+
+	return tags, fields
+}
+
+// GenerateSQLQuery builds an InfluxDB 3 SQL query selecting the fields, tags and time of v from table,
+// the measurement of v when table is empty. The tag values of v are bound as $tag<n> parameters.
+func (q *influxQu) GenerateSQLQuery(table string, rng TimeRange, v any, opts ...SQLOption) (string, influxdb3.QueryParameters, error) {
+	var o sqlOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if (o.aggregate != "" || o.binInterval != 0) && (o.binInterval <= 0 || !identifierPattern.MatchString(o.aggregate)) {
+		return "", nil, &InvalidQueryArgument{name: "aggregate", value: o.aggregate}
+	}
+
+	if err := rng.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	val, p, err := q.structPlan(v)
+	if err != nil {
+		return "", nil, err
+	}
+
+	measurement, tags, _, values, _, err := q.planData(val, p)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if table == "" {
+		table = measurement
+	}
+
+	if table == "" {
+		return "", nil, &NoValidMeasurement{}
+	}
+
+	tagColumns, fieldColumns := sqlColumns(p, values)
+	timeColumn := sqlIdentifier(sqlTimeColumn)
+
+	if o.aggregate != "" {
+		timeColumn = "date_bin(" + sqlInterval(o.binInterval) + ", " + timeColumn + ")"
+	}
+
+	columns := make([]string, 0, len(fieldColumns)+len(tagColumns)+1)
+	for _, f := range fieldColumns {
+		if o.aggregate != "" {
+			columns = append(columns, o.aggregate+"("+sqlIdentifier(f)+") AS "+sqlIdentifier(f))
+		} else {
+			columns = append(columns, sqlIdentifier(f))
+		}
+	}
+
+	for _, t := range tagColumns {
+		columns = append(columns, sqlIdentifier(t))
+	}
+
+	if o.aggregate != "" {
+		columns = append(columns, timeColumn+" AS "+sqlIdentifier(sqlTimeColumn))
+	} else {
+		columns = append(columns, timeColumn)
+	}
+
+	if o.aggregate == "" && (p.tagMap != nil || p.fieldMap != nil || p.hasExpand) {
+		columns = []string{"*"}
+	}
+
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM " + sqlIdentifier(table)
+
+	keys := sortedKeys(tags)
+	params := make(influxdb3.QueryParameters, len(keys))
+	conds := make([]string, 0, len(keys)+1)
+
+	for i, k := range keys {
+		name := "tag" + strconv.Itoa(i)
+		params[name] = tags[k]
+		conds = append(conds, sqlIdentifier(k)+" = $"+name)
+	}
+
+	if c := rng.SQL(); c != "" {
+		conds = append(conds, c)
+	}
+
+	if len(conds) != 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	if o.aggregate != "" {
+		group := make([]string, 0, len(tagColumns)+1)
+		for _, t := range tagColumns {
+			group = append(group, sqlIdentifier(t))
+		}
+
+		query += " GROUP BY " + strings.Join(append(group, timeColumn), ", ")
+	}
+
+	return query + " ORDER BY " + sqlIdentifier(sqlTimeColumn), params, nil
+}
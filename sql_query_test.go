@@ -0,0 +1,72 @@
+package influxqu
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_GenerateSQLQuery(t *testing.T) {
+	type Data struct {
+		Base  string  `influxqu:"measurement"`
+		Host  string  `influxqu:"tag,host"`
+		Zone  string  `influxqu:"tag,zone,omitempty"`
+		Usage float64 `influxqu:"field,usage"`
+		Load  int     `influxqu:"field,load"`
+	}
+
+	g := NewinfluxQu()
+	filter := Data{Base: `cpu"x`, Host: "h1' OR 1=1"}
+
+	q, params, err := g.GenerateSQLQuery("", LastN(time.Hour), &filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `SELECT "usage", "load", "host", "zone", "time" FROM "cpu""x" WHERE "host" = $tag0 AND "time" >= now() - interval '1 hours' ORDER BY "time"`
+	if q != expected {
+		t.Errorf("query is not expected, got: %s, expected: %s", q, expected)
+	}
+
+	if len(params) != 1 || params["tag0"] != "h1' OR 1=1" {
+		t.Errorf("params are not expected, got: %v", params)
+	}
+
+	filter.Load = 1
+
+	q, _, err = g.GenerateSQLQuery("cpu", TimeRange{}, &filter, WithDateBin(time.Minute, "avg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `SELECT avg("load") AS "load", "host", "zone", date_bin(interval '1 minutes', "time") AS "time" FROM "cpu" WHERE "host" = $tag0 ` +
+		`GROUP BY "host", "zone", date_bin(interval '1 minutes', "time") ORDER BY "time"`
+	if q != expected {
+		t.Errorf("query is not expected, got: %s, expected: %s", q, expected)
+	}
+
+	for _, opt := range []SQLOption{WithDateBin(time.Minute, "avg(1); DROP"), WithDateBin(time.Minute, ""), WithDateBin(-time.Minute, "avg")} {
+		if _, _, err := g.GenerateSQLQuery("cpu", TimeRange{}, &filter, opt); !errors.Is(err, ErrInvalidQueryArgument) {
+			t.Errorf("expected an invalid argument error, got: %v", err)
+		}
+	}
+
+	if _, _, err := g.GenerateSQLQuery("cpu", LastN(-time.Hour), &filter); !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("expected an invalid time range error, got: %v", err)
+	}
+
+	type Dynamic struct {
+		Host   string             `influxqu:"tag,host"`
+		Values map[string]float64 `influxqu:"fields"`
+	}
+
+	q, _, err = g.GenerateSQLQuery("", TimeRange{}, Dynamic{Host: "h"})
+	if !errors.Is(err, ErrNoValidMeasurement) {
+		t.Errorf("expected a no measurement error, got: %s, %v", q, err)
+	}
+
+	q, _, err = g.GenerateSQLQuery("metrics", TimeRange{}, Dynamic{Host: "h"})
+	if err != nil || q != `SELECT * FROM "metrics" WHERE "host" = $tag0 ORDER BY "time"` {
+		t.Errorf("query is not expected, got: %s, %v", q, err)
+	}
+}
@@ -0,0 +1,191 @@
+package influxqu
+
+import (
+	"strconv"
+	"time"
+)
+
+// timeBound is one end of a TimeRange, either an absolute time or an offset from now
+type timeBound struct {
+	set      bool
+	relative bool
+	offset   time.Duration
+	at       time.Time
+}
+
+func (b timeBound) resolve(now time.Time) time.Time {
+	if !b.set || b.relative {
+		return now.Add(b.offset)
+	}
+
+	return b.at
+}
+
+// TimeRange is the time range of a query, an unset stop is now
+type TimeRange struct {
+	start timeBound
+	stop  timeBound
+}
+
+// LastN is the range from d before now until now
+func LastN(d time.Duration) TimeRange {
+	return TimeRange{start: timeBound{set: true, relative: true, offset: -d}}
+}
+
+// Since is the range from start until now
+func Since(start time.Time) TimeRange {
+	return TimeRange{start: timeBound{set: true, at: start}}
+}
+
+// Between is the range from start until stop, stop is excluded
+func Between(start, stop time.Time) TimeRange {
+	return TimeRange{start: timeBound{set: true, at: start}, stop: timeBound{set: true, at: stop}}
+}
+
+// Relative is the range between two offsets from now, -2h and -1h is the hour before the last one
+func Relative(start, stop time.Duration) TimeRange {
+	return TimeRange{
+		start: timeBound{set: true, relative: true, offset: start},
+		stop:  timeBound{set: true, relative: true, offset: stop},
+	}
+}
+
+func (r TimeRange) IsZero() bool {
+	return !r.start.set && !r.stop.set
+}
+
+// Validate returns an InvalidTimeRange error unless start is before stop
+func (r TimeRange) Validate() error {
+	if r.IsZero() {
+		return nil
+	}
+
+	now := time.Now()
+	if !r.start.set || !r.start.resolve(now).Before(r.stop.resolve(now)) {
+		return &InvalidTimeRange{}
+	}
+
+	return nil
+}
+
+func fluxBound(b timeBound) string {
+	switch {
+	case !b.set:
+		return ""
+	case !b.relative:
+		return b.at.UTC().Format(time.RFC3339Nano)
+	case b.offset == 0:
+		return "now()"
+	case b.offset < 0:
+		return "-" + fluxDuration(-b.offset)
+	}
+
+	return fluxDuration(b.offset)
+}
+
+// exactUnit returns d in the largest unit of units that holds it exactly
+func exactUnit(d time.Duration, units []durationUnit) (int64, string) {
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return int64(d / u.unit), u.name
+		}
+	}
+
+	return int64(d), units[len(units)-1].name
+}
+
+type durationUnit struct {
+	unit time.Duration
+	name string
+}
+
+var (
+	sqlUnits = []durationUnit{
+		{time.Hour, "hours"}, {time.Minute, "minutes"}, {time.Second, "seconds"},
+		{time.Millisecond, "milliseconds"}, {time.Microsecond, "microseconds"}, {time.Nanosecond, "nanoseconds"},
+	}
+	influxQLUnits = []durationUnit{
+		{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"},
+		{time.Millisecond, "ms"}, {time.Microsecond, "u"}, {time.Nanosecond, "ns"},
+	}
+)
+
+func sqlBound(b timeBound) string {
+	if !b.relative {
+		return "'" + b.at.UTC().Format(time.RFC3339Nano) + "'"
+	}
+
+	if b.offset == 0 {
+		return "now()"
+	}
+
+	op, d := " + ", b.offset
+	if d < 0 {
+		op, d = " - ", -d
+	}
+
+	n, unit := exactUnit(d, sqlUnits)
+
+	return "now()" + op + "interval '" + strconv.FormatInt(n, 10) + " " + unit + "'"
+}
+
+func influxQLBound(b timeBound) string {
+	if !b.relative {
+		return "'" + b.at.UTC().Format(time.RFC3339Nano) + "'"
+	}
+
+	if b.offset == 0 {
+		return "now()"
+	}
+
+	op, d := " + ", b.offset
+	if d < 0 {
+		op, d = " - ", -d
+	}
+
+	n, unit := exactUnit(d, influxQLUnits)
+
+	return "now()" + op + strconv.FormatInt(n, 10) + unit
+}
+
+// predicate writes the time condition of column with the bound renderer, stop is excluded
+func (r TimeRange) predicate(column string, bound func(timeBound) string) string {
+	cond := ""
+	if r.start.set {
+		cond = column + " >= " + bound(r.start)
+	}
+
+	if r.stop.set {
+		if cond != "" {
+			cond += " AND "
+		}
+
+		cond += column + " < " + bound(r.stop)
+	}
+
+	return cond
+}
+
+// Flux returns the range stage of the time range, "" for a zero range
+func (r TimeRange) Flux() string {
+	switch {
+	case r.start.set && r.stop.set:
+		return "range(start: " + fluxBound(r.start) + ", stop: " + fluxBound(r.stop) + ")"
+	case r.start.set:
+		return "range(start: " + fluxBound(r.start) + ")"
+	case r.stop.set:
+		return "range(stop: " + fluxBound(r.stop) + ")"
+	}
+
+	return ""
+}
+
+// SQL returns the condition of the time range on the time column, "" for a zero range
+func (r TimeRange) SQL() string {
+	return r.predicate(`"time"`, sqlBound)
+}
+
+// InfluxQL returns the condition of the time range on time, "" for a zero range
+func (r TimeRange) InfluxQL() string {
+	return r.predicate("time", influxQLBound)
+}
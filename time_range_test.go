@@ -0,0 +1,83 @@
+package influxqu
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_TimeRange(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	stop := start.Add(90 * time.Minute)
+
+	cases := []struct {
+		rng                 TimeRange
+		flux, sql, influxQL string
+	}{
+		{
+			LastN(90 * time.Second),
+			"range(start: -1m30s)",
+			`"time" >= now() - interval '90 seconds'`,
+			"time >= now() - 90s",
+		},
+		{
+			Since(start),
+			"range(start: 2024-01-02T02:04:05Z)",
+			`"time" >= '2024-01-02T02:04:05Z'`,
+			"time >= '2024-01-02T02:04:05Z'",
+		},
+		{
+			Between(start, stop),
+			"range(start: 2024-01-02T02:04:05Z, stop: 2024-01-02T03:34:05Z)",
+			`"time" >= '2024-01-02T02:04:05Z' AND "time" < '2024-01-02T03:34:05Z'`,
+			"time >= '2024-01-02T02:04:05Z' AND time < '2024-01-02T03:34:05Z'",
+		},
+		{
+			Relative(-2*time.Hour, 0),
+			"range(start: -2h, stop: now())",
+			`"time" >= now() - interval '2 hours' AND "time" < now()`,
+			"time >= now() - 2h AND time < now()",
+		},
+		{
+			LastN(1500 * time.Millisecond),
+			"range(start: -1s500ms)",
+			`"time" >= now() - interval '1500 milliseconds'`,
+			"time >= now() - 1500ms",
+		},
+	}
+
+	for _, c := range cases {
+		if err := c.rng.Validate(); err != nil {
+			t.Errorf("%s: unexpected error %v", c.flux, err)
+		}
+
+		if got := c.rng.Flux(); got != c.flux {
+			t.Errorf("flux is not expected, got: %s, expected: %s", got, c.flux)
+		}
+
+		if got := c.rng.SQL(); got != c.sql {
+			t.Errorf("sql is not expected, got: %s, expected: %s", got, c.sql)
+		}
+
+		if got := c.rng.InfluxQL(); got != c.influxQL {
+			t.Errorf("influxql is not expected, got: %s, expected: %s", got, c.influxQL)
+		}
+	}
+
+	for _, rng := range []TimeRange{
+		Between(stop, start),
+		Between(start, start),
+		LastN(-time.Hour),
+		Since(time.Now().Add(time.Hour)),
+		Relative(-time.Hour, -2*time.Hour),
+	} {
+		if err := rng.Validate(); !errors.Is(err, ErrInvalidTimeRange) {
+			t.Errorf("expected an invalid time range error for %s, got: %v", rng.Flux(), err)
+		}
+	}
+
+	var zero TimeRange
+	if zero.Validate() != nil || zero.Flux() != "" || zero.SQL() != "" {
+		t.Error("zero range is not empty")
+	}
+}
@@ -3,9 +3,13 @@ package influxqu
 import (
 	"encoding"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"time"
 
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/float16"
 	"github.com/shopspring/decimal"
 )
 
@@ -50,9 +54,7 @@ func getTypeInfo(i interface{}, val reflect.Value) (reflect.Type, reflect.Kind)
 	return t, valKind
 }
 
-func getFieldAsString(val reflect.Value, i int) (string, error) {
-	f := val.Field(i)
-
+func getFieldAsString(f reflect.Value) (string, error) {
 	if f.Kind() == reflect.Ptr {
 		if f.IsNil() {
 			return "", nil
@@ -92,9 +94,33 @@ func getFieldAsString(val reflect.Value, i int) (string, error) {
 	return "", &UnSupportedType{}
 }
 
-func getFieldAsTime(val reflect.Value, i int) (time.Time, error) {
-	f := val.Field(i)
+func unixTime(n int64, unit time.Duration) time.Time {
+	switch unit {
+	case time.Second:
+		return time.Unix(n, 0)
+	case time.Millisecond:
+		return time.UnixMilli(n)
+	case time.Microsecond:
+		return time.UnixMicro(n)
+	}
+
+	return time.Unix(0, n)
+}
+
+func unixValue(t time.Time, unit time.Duration) int64 {
+	switch unit {
+	case time.Second:
+		return t.Unix()
+	case time.Millisecond:
+		return t.UnixMilli()
+	case time.Microsecond:
+		return t.UnixMicro()
+	}
+
+	return t.UnixNano()
+}
 
+func getFieldAsTime(pf *planField, f reflect.Value) (time.Time, error) {
 	if f.Kind() == reflect.Ptr {
 		if f.IsNil() {
 			return time.Time{}, &UnSupportedType{}
@@ -103,6 +129,15 @@ func getFieldAsTime(val reflect.Value, i int) (time.Time, error) {
 		f = f.Elem()
 	}
 
+	switch {
+	case pf.unixUnit != 0 && isIntKind(f.Kind()):
+		return unixTime(f.Int(), pf.unixUnit), nil
+	case pf.unixUnit != 0:
+		return unixTime(int64(f.Uint()), pf.unixUnit), nil
+	case pf.layout != "":
+		return time.Parse(pf.layout, f.String())
+	}
+
 	t, ok := f.Interface().(time.Time)
 	if !ok {
 		return time.Time{}, &UnSupportedType{}
@@ -110,3 +145,240 @@ func getFieldAsTime(val reflect.Value, i int) (time.Time, error) {
 
 	return t, nil
 }
+
+func setFieldValue(f reflect.Value, column string, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+
+		return setFieldValue(f.Elem(), column, v)
+	}
+
+	if u, ok := f.Addr().Interface().(InfluxFieldUnmarshaler); ok {
+		return u.UnmarshalInfluxField(v)
+	}
+
+	if s, ok := v.(string); ok && f.Kind() != reflect.String {
+		if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+
+	if f.Type().PkgPath() == decimalPkgPath && f.Type().Name() == decimalStructName {
+		d, err := toDecimal(v)
+		if err != nil {
+			return &MismatchedType{column: column, target: f.Type(), value: v}
+		}
+
+		f.Set(reflect.ValueOf(d))
+
+		return nil
+	}
+
+	if f.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := toTime(v)
+		if err != nil {
+			return &MismatchedType{column: column, target: f.Type(), value: v}
+		}
+
+		f.Set(reflect.ValueOf(t))
+
+		return nil
+	}
+
+	if !setBasicValue(f, v) {
+		return &MismatchedType{column: column, target: f.Type(), value: v}
+	}
+
+	return nil
+}
+
+func setBasicValue(f reflect.Value, v interface{}) bool {
+	switch f.Kind() {
+	case reflect.String:
+		switch s := v.(type) {
+		case string:
+			f.SetString(s)
+		case []byte:
+			f.SetString(string(s))
+		default:
+			return false
+		}
+
+		return true
+	case reflect.Bool:
+		return setBool(f, v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setInt(f, v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setUint(f, v)
+	case reflect.Float32, reflect.Float64:
+		return setFloat(f, v)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(f.Type()) {
+		f.Set(rv)
+		return true
+	}
+
+	return false
+}
+
+func setBool(f reflect.Value, v interface{}) bool {
+	switch b := v.(type) {
+	case bool:
+		f.SetBool(b)
+	case string:
+		tmp, err := strconv.ParseBool(b)
+		if err != nil {
+			return false
+		}
+
+		f.SetBool(tmp)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func setInt(f reflect.Value, v interface{}) bool {
+	var i int64
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i = rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return false
+		}
+
+		i = int64(rv.Uint())
+	case reflect.String:
+		tmp, err := strconv.ParseInt(rv.String(), 10, 64)
+		if err != nil {
+			return false
+		}
+
+		i = tmp
+	default:
+		return false
+	}
+
+	if f.OverflowInt(i) {
+		return false
+	}
+
+	f.SetInt(i)
+
+	return true
+}
+
+func setUint(f reflect.Value, v interface{}) bool {
+	var u uint64
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u = rv.Uint()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < 0 {
+			return false
+		}
+
+		u = uint64(rv.Int())
+	case reflect.String:
+		tmp, err := strconv.ParseUint(rv.String(), 10, 64)
+		if err != nil {
+			return false
+		}
+
+		u = tmp
+	default:
+		return false
+	}
+
+	if f.OverflowUint(u) {
+		return false
+	}
+
+	f.SetUint(u)
+
+	return true
+}
+
+func setFloat(f reflect.Value, v interface{}) bool {
+	fl, ok := toFloat64(v)
+	if !ok {
+		return false
+	}
+
+	f.SetFloat(fl)
+
+	return true
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	if h, ok := v.(float16.Num); ok {
+		return float64(h.Float32()), true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.String:
+		tmp, err := strconv.ParseFloat(rv.String(), 64)
+		return tmp, err == nil
+	}
+
+	return 0, false
+}
+
+func toDecimal(v interface{}) (decimal.Decimal, error) {
+	switch n := v.(type) {
+	case decimal.Decimal:
+		return n, nil
+	case float64:
+		return decimal.NewFromFloat(n), nil
+	case float32:
+		return decimal.NewFromFloat32(n), nil
+	case int64:
+		return decimal.NewFromInt(n), nil
+	case uint64:
+		return decimal.NewFromString(strconv.FormatUint(n, 10))
+	case string:
+		return decimal.NewFromString(n)
+	case []byte:
+		return decimal.NewFromString(string(n))
+	}
+
+	return decimal.Decimal{}, &UnSupportedType{}
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case arrow.Timestamp:
+		return t.ToTime(arrow.Nanosecond), nil
+	case string:
+		return time.Parse(time.RFC3339Nano, t)
+	}
+
+	return time.Time{}, &UnSupportedType{}
+}
@@ -0,0 +1,143 @@
+package influxqu
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	pointMarshalerType = reflect.TypeOf((*InfluxPointMarshaler)(nil)).Elem()
+	stringerType       = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType           = reflect.TypeOf(time.Time{})
+	bytesType          = reflect.TypeOf([]byte(nil))
+)
+
+var roleNames = [...]string{roleMeasurement: "measurement", roleTag: "tag", roleField: "field", roleTimestamp: "timestamp"}
+
+// basicType reports whether t is one of the unnamed types getFieldAsString converts
+func basicType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return t.PkgPath() == ""
+	}
+
+	return false
+}
+
+// supportedValueType reports whether the encoders accept the type t in the role of pf,
+// it follows the checks of cmd/influxqu-gen
+func supportedValueType(pf *planField, t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch pf.role {
+	case roleMeasurement, roleTag:
+		return pf.marshaler || t.Kind() == reflect.String || t.Implements(stringerType) ||
+			t.Implements(textMarshalerType) || basicType(t) || t == timeType
+	case roleTimestamp:
+		return pf.unixUnit != 0 || pf.layout != "" || t == timeType
+	}
+
+	if pf.expand {
+		if t = t.Elem(); t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+
+	if pf.marshaler || pf.isDecimal || isDecimalType(t) || t == timeType || t == bytesType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Map, reflect.Slice, reflect.Array, reflect.Struct,
+		reflect.Complex64, reflect.Complex128, reflect.UnsafePointer, reflect.Ptr:
+		return false
+	}
+
+	return true
+}
+
+// structField returns the field of t at index and its Go path
+func structField(t reflect.Type, index []int) (reflect.StructField, string) {
+	var f reflect.StructField
+
+	path := typeName(t)
+
+	for _, i := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		f = t.Field(i)
+		path += "." + f.Name
+		t = f.Type
+	}
+
+	return f, path
+}
+
+// Validate checks the struct tags of the type of v, a value, a pointer, a slice or a reflect.Type.
+// It returns a *SchemaError with every problem found, nil if the type can be encoded and decoded.
+func (q *influxQu) Validate(v any) error {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return &UnSupportedType{}
+	}
+
+	p := &typePlan{}
+	s := newPlanState(t)
+	s.collect = true
+
+	if err := q.compilePlan(p, s, t, nil, ""); err != nil {
+		return err
+	}
+
+	for i := range p.fields {
+		pf := &p.fields[i]
+
+		f, path := structField(t, pf.index)
+		if !supportedValueType(pf, f.Type) {
+			err := &UnsupportedFieldType{role: roleNames[pf.role], typ: f.Type}
+			s.errs = append(s.errs, &FieldError{path: path, tag: f.Tag.Get(q.key), err: err})
+		}
+
+		// an element name of an expanded field must not be a static field name
+		if pf.expand {
+			for _, name := range sortedKeys(s.fields) {
+				if _, ok := pf.expandIndex(name); ok {
+					s.errs = append(s.errs, &FieldError{path: path, tag: f.Tag.Get(q.key), err: &DuplicatedField{field: name}})
+				}
+			}
+		}
+	}
+
+	if !reflect.PointerTo(t).Implements(pointMarshalerType) {
+		if !s.measurement && !reflect.PointerTo(t).Implements(measurerType) {
+			s.errs = append(s.errs, &FieldError{path: typeName(t), err: &NoValidMeasurement{}})
+		}
+
+		if len(s.fields) == 0 && p.fieldMap == nil {
+			s.errs = append(s.errs, &FieldError{path: typeName(t), err: &NoValidField{}})
+		}
+	}
+
+	if len(s.errs) != 0 {
+		return &SchemaError{errs: s.errs}
+	}
+
+	return nil
+}
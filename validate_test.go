@@ -0,0 +1,167 @@
+package influxqu
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Validate(t *testing.T) {
+	type Tag struct {
+		T1 string `influxqu:"tag,t1"`
+		T2 string `influxqu:"tag,t1,omitempty"`
+	}
+
+	type Reading struct {
+		Value int `influxqu:"field,value,decimal=string"`
+	}
+
+	type Data struct {
+		Tag
+		Base  string    `influxqu:"measurement"`
+		Name  string    `influxqu:"measurement"`
+		F1    int       `influxqu:"field"`
+		Temp  Reading   `influxqu:"inline,prefix=temp_"`
+		Bad   int       `influxqu:"inline"`
+		Stamp time.Time `influxqu:"timestamp"`
+	}
+
+	g := NewinfluxQu()
+
+	e := g.Validate((*Data)(nil))
+
+	var schema *SchemaError
+	if !errors.As(e, &schema) {
+		t.Fatalf("expected a schema error, got: %v", e)
+	}
+
+	expected := []struct {
+		path, tag string
+		err       error
+	}{
+		{"Data.Tag.T2", "tag,t1,omitempty", ErrDuplicatedTag},
+		{"Data.Name", "measurement", ErrDuplicatedMeasurement},
+		{"Data.F1", "field", ErrNoFieldName},
+		{"Data.Temp.Value", "field,value,decimal=string", ErrUnSupportedTag},
+		{"Data.Bad", "inline", ErrUnSupportedTag},
+	}
+
+	if len(schema.Errors()) != len(expected) {
+		t.Fatalf("errors are not expected, got: %v", e)
+	}
+
+	for i, x := range expected {
+		got := schema.Errors()[i]
+		if got.Path() != x.path || got.Tag() != x.tag || !errors.Is(got, x.err) {
+			t.Errorf("error %d is not expected, got: %v", i, got)
+		}
+	}
+
+	if !errors.Is(e, ErrNoFieldName) || errors.Is(e, ErrNoTagName) {
+		t.Error("errors.Is does not match the aggregated errors")
+	}
+
+	if expected := "Data.Tag.T2 `tag,t1,omitempty`: duplicated tag t1"; schema.Errors()[0].Error() != expected {
+		t.Errorf("message is not expected, got: %s, expected: %s", schema.Errors()[0], expected)
+	}
+
+	type Empty struct {
+		T1 string `influxqu:"tag,t1"`
+	}
+
+	e = g.Validate(reflect.TypeOf([]Empty{}))
+	if !errors.Is(e, ErrNoValidMeasurement) || !errors.Is(e, ErrNoValidField) {
+		t.Errorf("expected missing measurement and field errors, got: %v", e)
+	}
+
+	type Valid struct {
+		Base string `influxqu:"measurement"`
+		F1   int    `influxqu:"field,f1"`
+	}
+
+	if e := g.Validate(Valid{}); e != nil {
+		t.Errorf("expected no error, got: %v", e)
+	}
+
+	if e := g.Validate(1); !errors.Is(e, ErrUnSupportedType) {
+		t.Errorf("expected an unsupported type error, got: %v", e)
+	}
+}
+
+func Test_Validate_Value_Types(t *testing.T) {
+	type Level int
+
+	type Data struct {
+		Base    string            `influxqu:"measurement"`
+		Cores   []int             `influxqu:"tag,cores"`
+		Level   Level             `influxqu:"tag,level"`
+		Stamp   string            `influxqu:"timestamp"`
+		Events  chan int          `influxqu:"field,events"`
+		Handler func()            `influxqu:"field,handler"`
+		Loads   []float64         `influxqu:"field,loads"`
+		Cpus    []chan int        `influxqu:"field,cpu,expand"`
+		Host    *string           `influxqu:"tag,host"`
+		Raw     []byte            `influxqu:"field,raw"`
+		Seen    time.Time         `influxqu:"tag,seen"`
+		Any     interface{}       `influxqu:"field,any"`
+		Usage   []float64         `influxqu:"field,usage,expand"`
+		Labels  map[string]string `influxqu:"tags"`
+		Elapsed time.Duration     `influxqu:"field,elapsed"`
+	}
+
+	g := NewinfluxQu()
+
+	e := g.Validate(Data{})
+
+	var schema *SchemaError
+	if !errors.As(e, &schema) {
+		t.Fatalf("expected a schema error, got: %v", e)
+	}
+
+	expected := []string{
+		"Data.Cores `tag,cores`: unsupported tag type []int",
+		"Data.Level `tag,level`: unsupported tag type influxqu.Level",
+		"Data.Stamp `timestamp`: unsupported timestamp type string",
+		"Data.Events `field,events`: unsupported field type chan int",
+		"Data.Handler `field,handler`: unsupported field type func()",
+		"Data.Loads `field,loads`: unsupported field type []float64",
+		"Data.Cpus `field,cpu,expand`: unsupported field type []chan int",
+	}
+
+	if len(schema.Errors()) != len(expected) {
+		t.Fatalf("errors are not expected, got: %v", e)
+	}
+
+	for i, x := range expected {
+		if got := schema.Errors()[i]; got.Error() != x || !errors.Is(got, ErrUnsupportedFieldType) || !errors.Is(got, ErrUnSupportedType) {
+			t.Errorf("error %d is not expected, got: %v, expected: %s", i, got, x)
+		}
+	}
+
+	data := Data{Base: "base", Level: 1, Elapsed: time.Second}
+	if _, err := g.GenerateInfluxPoint(&data); !errors.Is(err, ErrUnSupportedType) {
+		t.Errorf("expected an unsupported type error when encoding, got: %v", err)
+	}
+}
+
+func Test_Validate_Expand_Overlap(t *testing.T) {
+	type Data struct {
+		Base  string     `influxqu:"measurement"`
+		Total int        `influxqu:"field,core_1"`
+		Cores []float64  `influxqu:"field,core,expand"`
+		Pair  [2]float64 `influxqu:"field,pair,expand"`
+		Third int        `influxqu:"field,pair_2"`
+	}
+
+	e := NewinfluxQu().Validate(Data{})
+
+	var schema *SchemaError
+	if !errors.As(e, &schema) || len(schema.Errors()) != 1 {
+		t.Fatalf("expected one schema error, got: %v", e)
+	}
+
+	if got := schema.Errors()[0]; got.Path() != "Data.Cores" || !errors.Is(got, ErrDuplicatedField) || got.Error() != "Data.Cores `field,core,expand`: duplicated field core_1" {
+		t.Errorf("error is not expected, got: %v", got)
+	}
+}